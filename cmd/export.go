@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/psds-microservice/data-channel-service/internal/application"
+	"github.com/psds-microservice/data-channel-service/internal/config"
+	"github.com/psds-microservice/data-channel-service/internal/database"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var exportFilesCmd = &cobra.Command{
+	Use:   "export-files <session_id>",
+	Short: "Export all files of a session as a ZIP archive with manifest.json",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExportFiles,
+}
+
+func init() {
+	exportFilesCmd.Flags().StringP("output", "o", "", "archive path (default session-<id>.zip, \"-\" for stdout)")
+	rootCmd.AddCommand(exportFilesCmd)
+}
+
+func runExportFiles(cmd *cobra.Command, args []string) error {
+	sessionID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid session_id: %w", err)
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = "session-" + sessionID.String() + ".zip"
+	}
+
+	_ = godotenv.Load(".env")
+	_ = godotenv.Load("../.env")
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	db, err := database.Open(cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	store, _, err := application.NewStorage(cfg, db)
+	if err != nil {
+		return err
+	}
+	dataSvc := service.NewDataService(db, store, service.DefaultFilePolicy())
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if output != "-" {
+		if file, err = os.Create(output); err != nil {
+			return err
+		}
+		w = file
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	manifest, err := dataSvc.WriteArchive(ctx, w, sessionID)
+	if file != nil {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(output)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	included := 0
+	for _, e := range manifest.Files {
+		if e.Path != "" {
+			included++
+		}
+	}
+	if output != "-" {
+		log.Printf("export-files: %s: %d of %d files", output, included, len(manifest.Files))
+	}
+	return nil
+}
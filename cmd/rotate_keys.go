@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/psds-microservice/data-channel-service/internal/application"
+	"github.com/psds-microservice/data-channel-service/internal/config"
+	"github.com/psds-microservice/data-channel-service/internal/database"
+	"github.com/psds-microservice/data-channel-service/internal/envelope"
+	"github.com/spf13/cobra"
+)
+
+var rotateKeysCmd = &cobra.Command{
+	Use:   "rotate-keys",
+	Short: "Rewrap stored data keys with the active master key (blobs are not rewritten)",
+	RunE:  runRotateKeys,
+}
+
+func init() {
+	rootCmd.AddCommand(rotateKeysCmd)
+}
+
+func runRotateKeys(cmd *cobra.Command, args []string) error {
+	_ = godotenv.Load(".env")
+	_ = godotenv.Load("../.env")
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	km, err := application.NewKeyManager(cfg)
+	if err != nil {
+		return err
+	}
+	if km == nil {
+		return errors.New("rotate-keys: encryption is not configured (ENCRYPTION_KEYS or ENCRYPTION_KEY_FILE)")
+	}
+	if err := database.MigrateUp(cfg.DatabaseURL()); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	db, err := database.Open(cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	n, err := envelope.NewDBKeyStore(db).Rewrap(ctx, km)
+	log.Printf("rotate-keys: %d data keys rewrapped with %q", n, km.ActiveKeyID())
+	if err != nil {
+		return fmt.Errorf("rotate-keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_channel_files_sha256;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS sha256;
+DROP TABLE IF EXISTS file_blobs;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS file_blobs (
+  sha256 CHAR(64) PRIMARY KEY,
+  size_bytes BIGINT NOT NULL DEFAULT 0,
+  storage_path VARCHAR(512) NOT NULL,
+  ref_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS sha256 CHAR(64);
+CREATE INDEX IF NOT EXISTS idx_channel_files_sha256 ON channel_files(sha256);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_channel_files_preview_pending;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS preview_sizes;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS preview_status;
@@ -0,0 +1,3 @@
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS preview_status VARCHAR(16) NOT NULL DEFAULT 'none';
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS preview_sizes VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_channel_files_preview_pending ON channel_files(preview_status) WHERE preview_status = 'pending';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_channel_files_scan_pending;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS scan_signature;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS scan_status;
@@ -0,0 +1,6 @@
+-- Файлы, загруженные до появления проверки, считаются чистыми; новые ждут сканера.
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS scan_status VARCHAR(16) NOT NULL DEFAULT 'clean';
+ALTER TABLE channel_files ALTER COLUMN scan_status SET DEFAULT 'pending';
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS scan_signature VARCHAR(255);
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_channel_files_scan_pending ON channel_files(scan_status) WHERE scan_status IN ('pending', 'error');
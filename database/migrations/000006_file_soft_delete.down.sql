@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_channel_files_session_created;
+DROP INDEX IF EXISTS idx_channel_files_deleted_at;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS deleted_at;
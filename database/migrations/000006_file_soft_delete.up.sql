@@ -0,0 +1,3 @@
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_channel_files_deleted_at ON channel_files(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_channel_files_session_created ON channel_files(session_id, created_at DESC, id DESC);
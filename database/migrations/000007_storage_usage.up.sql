@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS storage_usage (
+  scope VARCHAR(16) NOT NULL,
+  scope_id UUID NOT NULL,
+  bytes_used BIGINT NOT NULL DEFAULT 0,
+  files_count BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (scope, scope_id)
+);
+
+INSERT INTO storage_usage (scope, scope_id, bytes_used, files_count)
+SELECT 'session', session_id, COALESCE(SUM(size_bytes), 0), COUNT(*)
+FROM channel_files WHERE deleted_at IS NULL GROUP BY session_id
+ON CONFLICT (scope, scope_id) DO NOTHING;
+
+INSERT INTO storage_usage (scope, scope_id, bytes_used, files_count)
+SELECT 'user', user_id, COALESCE(SUM(size_bytes), 0), COUNT(*)
+FROM channel_files WHERE deleted_at IS NULL GROUP BY user_id
+ON CONFLICT (scope, scope_id) DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE channel_files DROP COLUMN IF EXISTS original_sha256;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS metadata_stripped;
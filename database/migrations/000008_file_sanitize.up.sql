@@ -0,0 +1,3 @@
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS metadata_stripped BOOLEAN NOT NULL DEFAULT FALSE;
+-- SHA-256 исходника с метаданными, если политика разрешает его хранить (ссылка в file_blobs).
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS original_sha256 VARCHAR(64);
@@ -0,0 +1,10 @@
+-- Ключи данных объектов хранилища, обёрнутые мастер-ключом key_id (шифрование at rest).
+CREATE TABLE IF NOT EXISTS blob_keys (
+  object_key VARCHAR(512) PRIMARY KEY,
+  key_id VARCHAR(64) NOT NULL,
+  wrapped_key BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blob_keys_key_id ON blob_keys(key_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS upload_sessions (
+  id UUID PRIMARY KEY,
+  session_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  filename VARCHAR(255) NOT NULL,
+  content_type VARCHAR(128),
+  size_bytes BIGINT NOT NULL,
+  sha256 CHAR(64) NOT NULL,
+  object_key VARCHAR(512) NOT NULL,
+  multipart_id VARCHAR(1024),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  file_id UUID,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_session_id ON upload_sessions(session_id);
+-- Для сборщика брошенных загрузок.
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_open ON upload_sessions(expires_at) WHERE status IN ('pending', 'completing');
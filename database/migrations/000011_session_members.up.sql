@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS session_members (
+  session_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'participant',
+  added_by UUID,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (session_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_members_user_id ON session_members(user_id);
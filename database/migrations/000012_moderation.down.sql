@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS session_restrictions;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS session_restrictions (
+  session_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  kind VARCHAR(16) NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  reason VARCHAR(500),
+  created_by UUID NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (session_id, user_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS moderation_actions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  session_id UUID NOT NULL,
+  target_id UUID NOT NULL,
+  actor_id UUID NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  reason VARCHAR(500),
+  expires_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_session_id ON moderation_actions(session_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_channel_messages_session_seq;
+DROP INDEX IF EXISTS idx_channel_messages_seq;
+ALTER TABLE channel_messages DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,5 @@
+-- Порядковый номер сообщения: id событий SSE и точка возобновления (Last-Event-ID).
+ALTER TABLE channel_messages ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channel_messages_seq ON channel_messages(seq);
+CREATE INDEX IF NOT EXISTS idx_channel_messages_session_seq ON channel_messages(session_id, seq);
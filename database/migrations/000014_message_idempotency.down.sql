@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_channel_messages_idempotency;
+ALTER TABLE channel_messages DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,5 @@
+-- Ключ идемпотентности PublishMessage: повтор с тем же ключом от того же отправителя не создаёт сообщение.
+ALTER TABLE channel_messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(128);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channel_messages_idempotency
+  ON channel_messages(session_id, user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
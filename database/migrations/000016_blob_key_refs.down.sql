@@ -0,0 +1,4 @@
+DELETE FROM blob_keys WHERE ref <> '';
+ALTER TABLE blob_keys DROP CONSTRAINT IF EXISTS blob_keys_pkey;
+ALTER TABLE blob_keys ADD PRIMARY KEY (object_key);
+ALTER TABLE blob_keys DROP COLUMN IF EXISTS ref;
@@ -0,0 +1,5 @@
+-- Ключ данных каждой записи объекта — отдельная строка: ref из заголовка объекта ('' — объекты формата DCE1).
+ALTER TABLE blob_keys ADD COLUMN IF NOT EXISTS ref VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE blob_keys DROP CONSTRAINT IF EXISTS blob_keys_pkey;
+ALTER TABLE blob_keys ADD PRIMARY KEY (object_key, ref);
@@ -0,0 +1,2 @@
+ALTER TABLE channel_files DROP COLUMN IF EXISTS scan_retry_at;
+ALTER TABLE channel_files DROP COLUMN IF EXISTS scan_attempts;
@@ -0,0 +1,3 @@
+-- Повтор проверки файлов со scan_status = 'error' с экспоненциальной задержкой.
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS scan_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE channel_files ADD COLUMN IF NOT EXISTS scan_retry_at TIMESTAMP WITH TIME ZONE;
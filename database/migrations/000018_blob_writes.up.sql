@@ -0,0 +1,9 @@
+-- Отметки записи объектов: ставятся до записи в хранилище отдельным commit и снимаются после commit
+-- транзакции, сославшейся на объект. Оставшиеся после отката отметки разбирает FileGC.
+CREATE TABLE IF NOT EXISTS blob_writes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  sha256 CHAR(64) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_blob_writes_sha256 ON blob_writes(sha256);
+CREATE INDEX IF NOT EXISTS idx_blob_writes_created_at ON blob_writes(created_at);
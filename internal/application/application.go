@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,7 +18,10 @@ import (
 	"github.com/psds-microservice/data-channel-service/internal/database"
 	grpcserver "github.com/psds-microservice/data-channel-service/internal/grpc"
 	"github.com/psds-microservice/data-channel-service/internal/handler"
+	"github.com/psds-microservice/data-channel-service/internal/metrics"
+	"github.com/psds-microservice/data-channel-service/internal/scan"
 	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/internal/urlsign"
 	"github.com/psds-microservice/data-channel-service/pkg/constants"
 	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -52,12 +56,24 @@ func serveOpenAPISpec() http.HandlerFunc {
 	}
 }
 
+const grpcMsgOverhead = 1 << 20 // запас на прочие поля запроса
+
+// gatewayErrorHandler: превышение квоты отдаётся по REST как 413; прочие RESOURCE_EXHAUSTED
+// (отставшая подписка, лимиты частоты) остаются 429.
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if grpcserver.IsQuotaExceeded(err) {
+		err = &runtime.HTTPStatusError{HTTPStatus: http.StatusRequestEntityTooLarge, Err: err}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, err)
+}
+
 // API приложение: HTTP + gRPC серверы (режим api).
 type API struct {
-	cfg     *config.Config
-	httpSrv *http.Server
-	grpcSrv *grpc.Server
-	lis     net.Listener
+	cfg        *config.Config
+	httpSrv    *http.Server
+	grpcSrv    *grpc.Server
+	lis        net.Listener
+	background []func(ctx context.Context) // фоновые воркеры, живут до отмены ctx в Run
 }
 
 // NewAPI создаёт приложение для режима api.
@@ -74,35 +90,122 @@ func NewAPI(cfg *config.Config) (*API, error) {
 	}
 
 	hub := service.NewDataHub()
-	dataSvc := service.NewDataService(db)
+	limiter, err := NewRateLimiter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hub.SetRateLimiter(limiter)
+	filePolicy := service.DefaultFilePolicy()
+	filePolicy.MaxSizeBytes = cfg.Files.MaxSizeBytes
+	filePolicy.Allowed = cfg.Files.AllowedTypes
+	// Настроенные запреты дополняют встроенные: исполняемые типы не разрешить, добавив свой тип.
+	filePolicy.Denied = append(slices.Clone(service.DefaultDeniedTypes), cfg.Files.DeniedTypes...)
+	store, direct, err := NewStorage(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	dataSvc := service.NewDataService(db, store, filePolicy)
+	dataSvc.SetEventPublisher(hub)
+	if direct != nil {
+		dataSvc.SetDirectUploads(direct, cfg.Storage.UploadURLTTL, cfg.Storage.UploadPartSize, cfg.Storage.UploadMaxSizeBytes)
+	}
+	dataSvc.SetSanitizePolicy(service.SanitizePolicy{
+		Enabled:      cfg.Sanitize.Enabled,
+		KeepOriginal: cfg.Sanitize.KeepOriginal,
+	})
+	dataSvc.SetQuotas(service.Quotas{
+		SessionBytes: cfg.Quota.SessionBytes,
+		SessionFiles: cfg.Quota.SessionFiles,
+		UserBytes:    cfg.Quota.UserBytes,
+		UserFiles:    cfg.Quota.UserFiles,
+	})
+	switch cfg.Membership.Source {
+	case "local":
+		dataSvc.SetMembership(service.NewLocalMembers(db))
+	case "session-manager":
+		dataSvc.SetMembership(service.NewSessionManagerMembers(
+			cfg.Membership.SessionManagerURL, cfg.Membership.SessionManagerToken, cfg.Membership.CacheTTL))
+	}
+	if cfg.FileURL.Keys != "" {
+		keys, err := urlsign.ParseKeys(cfg.FileURL.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		signer, err := urlsign.NewSigner(keys)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		dataSvc.SetURLSigner(signer, cfg.FileURL.TTL, cfg.FileURL.MaxTTL)
+	}
+	moderation := service.NewPGModerationBus(db, cfg.DSN(), hub)
+	dataSvc.SetModerationBus(moderation)
+	background := []func(ctx context.Context){dataSvc.FileGC(cfg.Files.GCInterval, cfg.Files.GCGrace), moderation.Run}
+	if len(cfg.Thumbnails.Sizes) > 0 {
+		thumbs := service.NewThumbnailer(db, store, cfg.Thumbnails.Sizes, cfg.Thumbnails.Workers)
+		dataSvc.SetThumbnailer(thumbs)
+		background = append(background, thumbs.Run)
+	}
+	if cfg.Scan.Scanner == "clamd" {
+		clamd, err := scan.NewClamd(cfg.Scan.ClamdAddress, cfg.Scan.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("scanner: %w", err)
+		}
+		scans := service.NewScanWorker(dataSvc, clamd, cfg.Scan.Workers)
+		dataSvc.SetScanWorker(scans)
+		background = append(background, scans.Run)
+	}
+
+	authn, err := NewAuthenticator(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	grpcAddr := cfg.AppHost + ":" + cfg.GRPCPort
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		return nil, fmt.Errorf("grpc listen %s: %w (порт занят — остановите другой процесс или задайте GRPC_PORT в .env)", grpcAddr, err)
 	}
-	grpcSrv := grpc.NewServer()
+	// Лимит сообщения gRPC должен вмещать файл максимального размера (UploadFile передаёт content целиком).
+	grpcSrv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(int(filePolicy.MaxSizeBytes)+grpcMsgOverhead),
+		grpc.ChainUnaryInterceptor(grpcserver.UnaryAuthInterceptor(authn)),
+		grpc.ChainStreamInterceptor(grpcserver.StreamAuthInterceptor(authn)),
+	)
 	grpcImpl := grpcserver.NewServer(grpcserver.Deps{
-		Data: dataSvc,
+		Data:          dataSvc,
+		Hub:           hub,
+		MaxFrameBytes: cfg.WSMaxMessageSize,
 	})
 	data_channel_service.RegisterDataChannelServiceServer(grpcSrv, grpcImpl)
 	reflection.Register(grpcSrv)
 
-	gatewayMux := runtime.NewServeMux()
+	gatewayMux := runtime.NewServeMux(runtime.WithErrorHandler(gatewayErrorHandler))
 	if err := data_channel_service.RegisterDataChannelServiceHandlerServer(context.Background(), gatewayMux, grpcImpl); err != nil {
 		return nil, fmt.Errorf("register grpc-gateway: %w", err)
 	}
 
+	// Вне production без ALLOWED_ORIGINS браузерные запросы принимаются из любого источника.
+	allowedOrigins := cfg.AllowedOrigins
+	if len(allowedOrigins) == 0 && cfg.AppEnv != "production" {
+		allowedOrigins = []string{"*"}
+	}
+	origins, err := handler.NewOriginPolicy(allowedOrigins)
+	if err != nil {
+		return nil, fmt.Errorf("config: ALLOWED_ORIGINS: %w", err)
+	}
+
 	// Gin router для WebSocket
 	ginRouter := gin.New()
 	ginRouter.Use(gin.Recovery())
-	wsHandler := handler.NewWebSocketHandler(hub, dataSvc)
+	wsHandler := handler.NewWebSocketHandler(hub, dataSvc, origins)
+	ginRouter.GET("/ws/data/:session_id", wsHandler.ServeWS)
 	ginRouter.GET("/ws/data/:session_id/:user_id", wsHandler.ServeWS)
 
 	// Основной HTTP mux: health/ready/swagger через net/http, REST через grpc-gateway, WebSocket через Gin
 	mux := http.NewServeMux()
 	mux.HandleFunc(constants.PathHealth, handler.Health)
 	mux.HandleFunc(constants.PathReady, handler.Ready)
+	mux.Handle(constants.PathMetrics, metrics.Handler())
 	mux.HandleFunc(constants.PathSwagger+"/openapi.json", serveOpenAPISpec())
 	mux.Handle(constants.PathSwagger+"/", httpSwagger.Handler(
 		httpSwagger.URL("openapi.json"),
@@ -110,18 +213,41 @@ func NewAPI(cfg *config.Config) (*API, error) {
 		httpSwagger.DocExpansion("list"),
 	))
 	// WebSocket через Gin
-	mux.Handle("/ws/", ginRouter)
+	mux.Handle("/ws/", handler.RequireAuth(authn, ginRouter))
 	// POST /data/file с multipart/form-data — отдельный handler для совместимости с тестами и клиентами
 	uploadMultipart := handler.UploadFileMultipart(dataSvc)
+	sessionArchive := handler.SessionArchive(dataSvc)
 	dataFileHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/data/file" && r.Method == http.MethodPost &&
 			strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "multipart/form-data") {
 			uploadMultipart(w, r)
 			return
 		}
+		// GET /data/{session_id}/files.zip: шаблоном ServeMux не выразить — пересекается с /data/file/{id}.
+		if sessionID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/data/"), "/files.zip"); ok &&
+			r.Method == http.MethodGet && !strings.Contains(sessionID, "/") {
+			r.SetPathValue("session_id", sessionID)
+			sessionArchive(w, r)
+			return
+		}
 		gatewayMux.ServeHTTP(w, r)
 	})
-	mux.Handle("/", dataFileHandler)
+	// REST проходит аутентификацию здесь: grpc-gateway вызывает сервер в процессе, минуя gRPC-интерсепторы.
+	// CORS — снаружи: preflight-запросы приходят без токена.
+	mux.Handle("/", handler.CORS(origins, handler.RequireAuth(authn, dataFileHandler)))
+	// Подписанная ссылка сама подтверждает доступ к содержимому; без подписи ссылок нужен токен.
+	contentAuth := handler.RequireAuth
+	if cfg.FileURL.Keys != "" {
+		contentAuth = handler.OptionalAuth
+	}
+	mux.Handle("GET /sse/data/{session_id}", handler.CORS(origins, handler.RequireAuth(authn, handler.SSEStream(hub, dataSvc))))
+	mux.Handle("POST /sse/data/{session_id}", handler.CORS(origins, handler.RequireAuth(authn, handler.SendFrame(hub, dataSvc, cfg.WSMaxMessageSize))))
+	pollers := service.NewPollers(hub)
+	background = append(background, pollers.Run)
+	mux.Handle("GET /poll/data/{session_id}", handler.CORS(origins, handler.RequireAuth(authn, handler.Poll(pollers, dataSvc))))
+	mux.Handle("POST /poll/data/{session_id}", handler.CORS(origins, handler.RequireAuth(authn, handler.SendFrame(hub, dataSvc, cfg.WSMaxMessageSize))))
+	mux.Handle("GET /data/file/{id}", handler.CORS(origins, contentAuth(authn, handler.DownloadFile(dataSvc))))
+	mux.Handle("GET /data/file/{id}/thumbnail", handler.CORS(origins, contentAuth(authn, handler.Thumbnail(dataSvc))))
 
 	httpAddr := cfg.AppHost + ":" + cfg.HTTPPort
 	httpSrv := &http.Server{
@@ -134,10 +260,11 @@ func NewAPI(cfg *config.Config) (*API, error) {
 	}
 
 	return &API{
-		cfg:     cfg,
-		httpSrv: httpSrv,
-		grpcSrv: grpcSrv,
-		lis:     lis,
+		cfg:        cfg,
+		httpSrv:    httpSrv,
+		grpcSrv:    grpcSrv,
+		lis:        lis,
+		background: background,
 	}, nil
 }
 
@@ -155,8 +282,32 @@ func (a *API) Run(ctx context.Context) error {
 	log.Printf("  Swagger spec:  %s/swagger/openapi.json", base)
 	log.Printf("  Health:        %s/health", base)
 	log.Printf("  Ready:         %s/ready", base)
+	log.Printf("  Metrics:       %s/metrics", base)
 	log.Printf("  WebSocket:     ws://%s:%s/ws/data/:session_id/:user_id", host, a.cfg.HTTPPort)
+	if a.cfg.AuthEnabled() {
+		log.Printf("  Auth:          bearer token required (WebSocket: /ws/data/:session_id)")
+	}
+	log.Printf("  SSE:           %s/sse/data/:session_id (POST sends a message)", base)
+	log.Printf("  Long-polling:  %s/poll/data/:session_id?cursor=&timeout= (POST sends a message)", base)
+	switch {
+	case len(a.cfg.AllowedOrigins) > 0:
+		log.Printf("  Origins:       %s", strings.Join(a.cfg.AllowedOrigins, ", "))
+	case a.cfg.AppEnv == "production":
+		log.Printf("  Origins:       same origin only")
+	default:
+		log.Printf("  Origins:       any (set ALLOWED_ORIGINS to restrict)")
+	}
 	log.Printf("  REST API:      %s/data/", base)
+	log.Printf("  Download:      %s/data/file/:id", base)
+	log.Printf("  Thumbnail:     %s/data/file/:id/thumbnail?size=", base)
+	log.Printf("  Archive:       %s/data/:session_id/files.zip", base)
+	log.Printf("  Members:       %s/data/:session_id/members (membership: %s)", base, a.cfg.Membership.Source)
+	if a.cfg.Storage.Backend == "s3" {
+		log.Printf("  Uploads:       %s/data/uploads (direct to s3://%s)", base, a.cfg.Storage.S3Bucket)
+	}
+	if a.cfg.Encryption.Keys != "" || a.cfg.Encryption.KeyFile != "" {
+		log.Printf("  Storage:       encrypted at rest")
+	}
 	log.Printf("gRPC server listening on %s", grpcAddr)
 	log.Printf("  gRPC endpoint: %s (reflection enabled)", grpcAddr)
 
@@ -172,6 +323,10 @@ func (a *API) Run(ctx context.Context) error {
 		}
 	}()
 
+	for _, run := range a.background {
+		go run(ctx)
+	}
+
 	<-ctx.Done()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,43 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"github.com/psds-microservice/data-channel-service/internal/config"
+)
+
+// NewAuthenticator собирает проверку токенов по конфигурации; nil — аутентификация выключена.
+func NewAuthenticator(cfg *config.Config) (auth.Authenticator, error) {
+	if !cfg.AuthEnabled() {
+		return nil, nil
+	}
+	var chain auth.Chain
+	var keys auth.KeySets
+	if cfg.Auth.JWKSFile != "" {
+		jwks, err := auth.LoadJWKSFile(cfg.Auth.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+		keys = append(keys, jwks)
+	}
+	if cfg.Auth.HS256Secret != "" {
+		keys = append(keys, auth.StaticKeys{{Alg: auth.AlgHS256, Key: []byte(cfg.Auth.HS256Secret)}})
+	}
+	if len(keys) > 0 {
+		chain.JWT = auth.NewJWT(keys, auth.JWTConfig{
+			Issuer:   cfg.Auth.Issuer,
+			Audience: cfg.Auth.Audience,
+			Leeway:   cfg.Auth.ClockSkew,
+		})
+	}
+	if cfg.Auth.IntrospectionURL != "" {
+		chain.Opaque = auth.NewIntrospection(auth.IntrospectionConfig{
+			URL:          cfg.Auth.IntrospectionURL,
+			ClientID:     cfg.Auth.IntrospectionClientID,
+			ClientSecret: cfg.Auth.IntrospectionClientSecret,
+			CacheTTL:     cfg.Auth.IntrospectionCacheTTL,
+		})
+	}
+	return chain, nil
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/psds-microservice/data-channel-service/internal/config"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+// NewRateLimiter собирает лимиты входящих WebSocket-кадров; nil — лимиты не заданы ни для одной области.
+func NewRateLimiter(cfg *config.Config) (*service.RateLimiter, error) {
+	rc := service.RateLimitConfig{
+		MaxViolations:   cfg.RateLimit.MaxViolations,
+		ViolationWindow: cfg.RateLimit.ViolationWindow,
+	}
+	for _, scope := range []struct {
+		env  string
+		spec string
+		dst  *service.RateLimits
+	}{
+		{"RATE_LIMIT_CONN", cfg.RateLimit.Conn, &rc.Conn},
+		{"RATE_LIMIT_USER", cfg.RateLimit.User, &rc.User},
+		{"RATE_LIMIT_SESSION", cfg.RateLimit.Session, &rc.Session},
+	} {
+		limits, err := service.ParseRateLimits(scope.spec)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", scope.env, err)
+		}
+		*scope.dst = limits
+	}
+	if len(rc.Conn) == 0 && len(rc.User) == 0 && len(rc.Session) == 0 {
+		return nil, nil
+	}
+	return service.NewRateLimiter(rc), nil
+}
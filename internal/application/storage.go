@@ -0,0 +1,64 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/psds-microservice/data-channel-service/internal/config"
+	"github.com/psds-microservice/data-channel-service/internal/envelope"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+	"gorm.io/gorm"
+)
+
+// NewStorage создаёт хранилище содержимого по конфигурации; при заданных мастер-ключах
+// объекты шифруются (ключи данных — в blob_keys). Вторым значением возвращается хранилище
+// для прямых загрузок по подписанным ссылкам (nil, если бэкенд их не поддерживает): клиенты
+// пишут в него открытые промежуточные объекты, минуя шифрование. Используется сервером и CLI-командами.
+func NewStorage(cfg *config.Config, db *gorm.DB) (storage.Storage, storage.DirectUploader, error) {
+	var base storage.Storage
+	var direct storage.DirectUploader
+	switch cfg.Storage.Backend {
+	case "s3":
+		s3, err := storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			PathStyle: cfg.Storage.S3PathStyle,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		base, direct = s3, s3
+	default:
+		local, err := storage.NewLocal(cfg.StorageDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("storage: %w", err)
+		}
+		base = local
+	}
+	km, err := NewKeyManager(cfg)
+	if err != nil || km == nil {
+		return base, direct, err
+	}
+	return envelope.NewStorage(base, km, envelope.NewDBKeyStore(db)), direct, nil
+}
+
+// NewKeyManager загружает мастер-ключи шифрования; nil — шифрование выключено.
+func NewKeyManager(cfg *config.Config) (envelope.KeyManager, error) {
+	switch {
+	case cfg.Encryption.KeyFile != "":
+		km, err := envelope.LoadKeyFile(cfg.Encryption.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %w", err)
+		}
+		return km, nil
+	case cfg.Encryption.Keys != "":
+		km, err := envelope.ParseKeys(cfg.Encryption.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %w", err)
+		}
+		return km, nil
+	}
+	return nil, nil
+}
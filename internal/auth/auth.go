@@ -0,0 +1,150 @@
+// Package auth проверяет токены доступа (JWT или opaque-токены через introspection)
+// и передаёт аутентифицированного пользователя через context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoToken      = errors.New("missing access token")
+	ErrInvalidToken = errors.New("invalid access token")
+)
+
+// WebSocketProtocol — подпротокол, за которым браузерный клиент передаёт токен:
+// Sec-WebSocket-Protocol: bearer, <token>. Сервер подтверждает только "bearer".
+const WebSocketProtocol = "bearer"
+
+// Identity — аутентифицированный пользователь: Subject из claim "sub" и остальные claims токена.
+type Identity struct {
+	Subject uuid.UUID
+	Claims  map[string]interface{}
+}
+
+// Authenticator проверяет токен доступа и возвращает его владельца.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// SessionRole — роль из токена: claim "session_roles" ({"<session_id>": "<role>"}) для этой сессии,
+// иначе общий claim "role"; "" — роль в токене не указана.
+func (id *Identity) SessionRole(sessionID uuid.UUID) string {
+	if roles, ok := id.Claims["session_roles"].(map[string]interface{}); ok {
+		if role, ok := roles[sessionID.String()].(string); ok {
+			return role
+		}
+	}
+	role, _ := id.Claims["role"].(string)
+	return role
+}
+
+// ScopePublish — scope сервисных токенов, которым разрешено публиковать сообщения от имени системы
+// и других отправителей (PublishMessage).
+const ScopePublish = "data:publish"
+
+// ScopeSubscribe — scope сервисных токенов, которым разрешено наблюдать за сессиями (Subscribe).
+const ScopeSubscribe = "data:subscribe"
+
+// ScopeMembers — scope сервисных токенов, которым разрешено менять состав любой сессии
+// (в том числе назначать первого владельца).
+const ScopeMembers = "data:members"
+
+// HasScope сообщает, выдан ли токену scope: claim "scope" (через пробел) или "scp" (строка или список).
+func (id *Identity) HasScope(scope string) bool {
+	for _, name := range []string{"scope", "scp"} {
+		switch v := id.Claims[name].(type) {
+		case string:
+			for _, s := range strings.Fields(v) {
+				if s == scope {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, s := range v {
+				if s == scope {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// NewContext возвращает ctx с аутентифицированным пользователем.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext возвращает пользователя, сохранённого NewContext.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok && id != nil
+}
+
+// Chain выбирает проверку по виду токена: JWT (три части через точку) — JWT, остальное — Opaque.
+// Любое из полей может быть nil.
+type Chain struct {
+	JWT    Authenticator
+	Opaque Authenticator
+}
+
+func (c Chain) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	if c.JWT != nil && strings.Count(token, ".") == 2 {
+		return c.JWT.Authenticate(ctx, token)
+	}
+	if c.Opaque != nil {
+		return c.Opaque.Authenticate(ctx, token)
+	}
+	return nil, ErrInvalidToken
+}
+
+// TokenFromRequest извлекает токен из заголовка Authorization: Bearer. Для WebSocket-upgrade
+// (браузер не может задать заголовок) принимаются также подпротокол "bearer, <token>" и query access_token,
+// для потока SSE (EventSource, Accept: text/event-stream) — query access_token.
+func TokenFromRequest(r *http.Request) string {
+	if token, ok := BearerToken(r.Header.Get("Authorization")); ok {
+		return token
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") && r.Method == http.MethodGet {
+		return r.URL.Query().Get("access_token")
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+	if protocols := websocketProtocols(r); len(protocols) == 2 && protocols[0] == WebSocketProtocol {
+		return protocols[1]
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// BearerToken разбирает значение "Bearer <token>".
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func websocketProtocols(r *http.Request) []string {
+	var out []string
+	for _, h := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(h, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
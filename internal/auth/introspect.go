@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	introspectionTimeout  = 5 * time.Second
+	introspectionCacheMax = 10000
+)
+
+// IntrospectionConfig — endpoint RFC 7662 и учётные данные клиента (Basic auth, если заданы).
+type IntrospectionConfig struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+	CacheTTL     time.Duration // сколько помнить ответ; 0 — без кэша
+}
+
+// Introspection проверяет opaque-токены запросом к authorization server (RFC 7662).
+// Активные ответы кэшируются по SHA-256 токена на CacheTTL, но не дольше exp токена.
+type Introspection struct {
+	cfg    IntrospectionConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[[32]byte]cachedIdentity
+}
+
+type cachedIdentity struct {
+	id      *Identity
+	expires time.Time
+}
+
+// NewIntrospection создаёт проверку opaque-токенов.
+func NewIntrospection(cfg IntrospectionConfig) *Introspection {
+	return &Introspection{
+		cfg:    cfg,
+		client: &http.Client{Timeout: introspectionTimeout},
+		cache:  map[[32]byte]cachedIdentity{},
+	}
+}
+
+func (in *Introspection) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	in.mu.Lock()
+	c, ok := in.cache[key]
+	in.mu.Unlock()
+	if ok && now.Before(c.expires) {
+		return c.id, nil
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, in.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if in.cfg.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(in.cfg.ClientID), url.QueryEscape(in.cfg.ClientSecret))
+	}
+	resp, err := in.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection: unexpected status %s", resp.Status)
+	}
+	var claims map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return nil, fmt.Errorf("%w: token is not active", ErrInvalidToken)
+	}
+	id, err := identityFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	expires := now.Add(in.cfg.CacheTTL)
+	if exp, ok := numericClaim(claims, "exp"); ok {
+		if !now.Before(exp) {
+			return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+		}
+		if exp.Before(expires) {
+			expires = exp
+		}
+	}
+	if in.cfg.CacheTTL > 0 {
+		in.remember(key, cachedIdentity{id: id, expires: expires}, now)
+	}
+	return id, nil
+}
+
+func (in *Introspection) remember(key [32]byte, c cachedIdentity, now time.Time) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if len(in.cache) >= introspectionCacheMax {
+		for k, v := range in.cache {
+			if !now.Before(v.expires) {
+				delete(in.cache, k)
+			}
+		}
+		if len(in.cache) >= introspectionCacheMax {
+			in.cache = map[[32]byte]cachedIdentity{}
+		}
+	}
+	in.cache[key] = c
+}
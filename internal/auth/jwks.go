@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwksCheckInterval — как часто JWKSFile проверяет, не изменился ли файл.
+const jwksCheckInterval = 30 * time.Second
+
+// ParseJWKS разбирает JWK Set (RFC 7517). Поддерживаются RSA (RS256), EC P-256 (ES256) и oct (HS256);
+// ключи с use=enc пропускаются.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	var keys []Key
+	for i, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		var k Key
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			k, err = rsaKey(jwk.N, jwk.E)
+		case "EC":
+			k, err = ecKey(jwk.Crv, jwk.X, jwk.Y)
+		case "oct":
+			k, err = octKey(jwk.K)
+		default:
+			err = fmt.Errorf("unsupported kty %q", jwk.Kty)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %d (kid %q): %w", i, jwk.Kid, err)
+		}
+		if jwk.Alg != "" && jwk.Alg != k.Alg {
+			return nil, fmt.Errorf("jwks: key %d (kid %q): alg %q does not match kty %s", i, jwk.Kid, jwk.Alg, jwk.Kty)
+		}
+		k.ID = jwk.Kid
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no signing keys")
+	}
+	return keys, nil
+}
+
+func rsaKey(n, e string) (Key, error) {
+	nb, err1 := base64.RawURLEncoding.DecodeString(n)
+	eb, err2 := base64.RawURLEncoding.DecodeString(e)
+	if err1 != nil || err2 != nil || len(nb) == 0 || len(eb) == 0 || len(eb) > 4 {
+		return Key{}, errors.New("invalid RSA n/e")
+	}
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(new(big.Int).SetBytes(eb).Int64())}
+	if pub.N.BitLen() < 2048 {
+		return Key{}, errors.New("RSA key shorter than 2048 bits")
+	}
+	return Key{Alg: AlgRS256, Key: pub}, nil
+}
+
+func ecKey(crv, x, y string) (Key, error) {
+	if crv != "P-256" {
+		return Key{}, fmt.Errorf("unsupported curve %q", crv)
+	}
+	xb, err1 := base64.RawURLEncoding.DecodeString(x)
+	yb, err2 := base64.RawURLEncoding.DecodeString(y)
+	if err1 != nil || err2 != nil || len(xb) != 32 || len(yb) != 32 {
+		return Key{}, errors.New("invalid EC x/y")
+	}
+	pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, xb...), yb...))
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Alg: AlgES256, Key: pub}, nil
+}
+
+func octKey(k string) (Key, error) {
+	secret, err := base64.RawURLEncoding.DecodeString(k)
+	if err != nil || len(secret) < 32 {
+		return Key{}, errors.New("oct key must be at least 32 bytes")
+	}
+	return Key{Alg: AlgHS256, Key: secret}, nil
+}
+
+// JWKSFile — ключи из локального JWKS-файла. Файл перечитывается при изменении (не чаще jwksCheckInterval),
+// поэтому ротация ключей у провайдера не требует перезапуска; при ошибке разбора остаются прежние ключи.
+type JWKSFile struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    []Key
+	modTime time.Time
+	checked time.Time
+}
+
+// LoadJWKSFile читает JWKS-файл; ошибка, если файл не читается или не содержит ключей.
+func LoadJWKSFile(path string) (*JWKSFile, error) {
+	f := &JWKSFile{path: path}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *JWKSFile) Keys() []Key {
+	f.mu.RLock()
+	keys, stale := f.keys, time.Since(f.checked) > jwksCheckInterval
+	f.mu.RUnlock()
+	if stale {
+		if err := f.reload(); err != nil {
+			log.Printf("auth: jwks %s: %v (keeping previous keys)", f.path, err)
+		}
+		f.mu.RLock()
+		keys = f.keys
+		f.mu.RUnlock()
+	}
+	return keys
+}
+
+func (f *JWKSFile) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checked = time.Now()
+	st, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.keys != nil && st.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	f.keys, f.modTime = keys, st.ModTime()
+	return nil
+}
+
+// KeySets объединяет несколько наборов ключей.
+type KeySets []KeySet
+
+func (s KeySets) Keys() []Key {
+	var out []Key
+	for _, set := range s {
+		out = append(out, set.Keys()...)
+	}
+	return out
+}
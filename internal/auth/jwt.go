@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Поддерживаемые алгоритмы подписи JWT.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// Key — ключ проверки подписи: []byte для HS256, *rsa.PublicKey для RS256, *ecdsa.PublicKey (P-256) для ES256.
+type Key struct {
+	ID  string // kid; пусто — ключ проверяет токены с любым kid
+	Alg string
+	Key crypto.PublicKey
+}
+
+// KeySet — источник ключей проверки; Keys вызывается на каждый токен.
+type KeySet interface {
+	Keys() []Key
+}
+
+// StaticKeys — неизменяемый набор ключей.
+type StaticKeys []Key
+
+func (k StaticKeys) Keys() []Key { return k }
+
+// JWTConfig — требования к токену. Пустые Issuer и Audience не проверяются.
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration // допуск расхождения часов для exp/nbf
+}
+
+// JWT проверяет подписанные JWT (HS256, RS256, ES256). exp обязателен, sub должен быть UUID пользователя.
+type JWT struct {
+	keys KeySet
+	cfg  JWTConfig
+	now  func() time.Time
+}
+
+// NewJWT создаёт проверку JWT по набору ключей.
+func NewJWT(keys KeySet, cfg JWTConfig) *JWT {
+	return &JWT{keys: keys, cfg: cfg, now: time.Now}
+}
+
+func (j *JWT) Authenticate(_ context.Context, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	signed := parts[0] + "." + parts[1]
+	if err := j.verify(header.Alg, header.Kid, signed, sig); err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	return j.identity(claims)
+}
+
+// verify ищет ключ с тем же алгоритмом (и kid, если задан) и проверяет подпись.
+// Тип ключа определяется алгоритмом ключа, а не заголовком токена, поэтому подмена RS256 на HS256 невозможна.
+func (j *JWT) verify(alg, kid, signed string, sig []byte) error {
+	switch alg {
+	case AlgHS256, AlgRS256, AlgES256:
+	default:
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, alg)
+	}
+	found := false
+	for _, k := range j.keys.Keys() {
+		if k.Alg != alg || (kid != "" && k.ID != "" && k.ID != kid) {
+			continue
+		}
+		found = true
+		if verifySignature(k, signed, sig) {
+			return nil
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: no key for alg %s kid %q", ErrInvalidToken, alg, kid)
+	}
+	return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+}
+
+func verifySignature(k Key, signed string, sig []byte) bool {
+	switch k.Alg {
+	case AlgHS256:
+		secret, ok := k.Key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), sig)
+	case AlgRS256:
+		pub, ok := k.Key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		sum := sha256.Sum256([]byte(signed))
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig) == nil
+	case AlgES256:
+		pub, ok := k.Key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		sum := sha256.Sum256([]byte(signed))
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, sum[:], r, s)
+	}
+	return false
+}
+
+func (j *JWT) identity(claims map[string]interface{}) (*Identity, error) {
+	now := j.now()
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return nil, fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+	if now.After(exp.Add(j.cfg.Leeway)) {
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(j.cfg.Leeway).Before(nbf) {
+		return nil, fmt.Errorf("%w: token not yet valid", ErrInvalidToken)
+	}
+	if j.cfg.Issuer != "" && claims["iss"] != j.cfg.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if j.cfg.Audience != "" && !hasAudience(claims["aud"], j.cfg.Audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return identityFromClaims(claims)
+}
+
+// identityFromClaims извлекает пользователя из claim "sub" (UUID).
+func identityFromClaims(claims map[string]interface{}) (*Identity, error) {
+	sub, _ := claims["sub"].(string)
+	subject, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sub must be a user UUID", ErrInvalidToken)
+	}
+	return &Identity{Subject: subject, Claims: claims}, nil
+}
+
+func numericClaim(claims map[string]interface{}, name string) (time.Time, bool) {
+	v, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// hasAudience: aud — строка или массив строк (RFC 7519, 4.1.3).
+func hasAudience(aud interface{}, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []interface{}:
+		for _, a := range v {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("bad encoding")
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testNow    = time.Unix(1_800_000_000, 0)
+	testSecret = []byte(strings.Repeat("s", 32))
+	testUser   = uuid.MustParse("5b1f5a8e-3d1c-4f0e-9a47-2f6c1d3b8e90")
+)
+
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{rsa: rk, ec: ek}
+}
+
+func b64(v []byte) string { return base64.RawURLEncoding.EncodeToString(v) }
+
+func segment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b64(data)
+}
+
+// sign собирает JWT и подписывает его алгоритмом header["alg"] (HS256 — секретом testSecret).
+func (k testKeys) sign(t *testing.T, header map[string]interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	signed := segment(t, header) + "." + segment(t, claims)
+	sum := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch header["alg"] {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, testSecret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case AlgRS256:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, sum[:]); err != nil {
+			t.Fatal(err)
+		}
+	case AlgES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.ec, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": testUser.String(),
+		"iss": "https://idp.example.com",
+		"aud": "data-channel",
+		"exp": float64(testNow.Add(time.Hour).Unix()),
+	}
+}
+
+func with(claims map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	set := StaticKeys{
+		{ID: "hs", Alg: AlgHS256, Key: testSecret},
+		{ID: "rs", Alg: AlgRS256, Key: &keys.rsa.PublicKey},
+		{ID: "es", Alg: AlgES256, Key: &keys.ec.PublicKey},
+	}
+	j := NewJWT(set, JWTConfig{Issuer: "https://idp.example.com", Audience: "data-channel", Leeway: 30 * time.Second})
+	j.now = func() time.Time { return testNow }
+
+	hs := map[string]interface{}{"alg": AlgHS256, "kid": "hs"}
+	rs := map[string]interface{}{"alg": AlgRS256, "kid": "rs"}
+	es := map[string]interface{}{"alg": AlgES256, "kid": "es"}
+	valid := keys.sign(t, rs, validClaims())
+	parts := strings.Split(valid, ".")
+
+	// Подмена алгоритма: токен HS256, подписанный открытым ключом RSA как секретом.
+	pubBytes := keys.rsa.PublicKey.N.Bytes()
+	confused := segment(t, map[string]interface{}{"alg": AlgHS256, "kid": "rs"}) + "." + parts[1]
+	mac := hmac.New(sha256.New, pubBytes)
+	mac.Write([]byte(confused))
+	confused += "." + b64(mac.Sum(nil))
+
+	cases := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "HS256", token: keys.sign(t, hs, validClaims())},
+		{name: "RS256", token: valid},
+		{name: "ES256", token: keys.sign(t, es, validClaims())},
+		{name: "no kid", token: keys.sign(t, map[string]interface{}{"alg": AlgES256}, validClaims())},
+		{name: "audience list", token: keys.sign(t, rs, with(validClaims(), "aud", []string{"other", "data-channel"}))},
+		{name: "expired within leeway", token: keys.sign(t, rs, with(validClaims(), "exp", float64(testNow.Add(-20*time.Second).Unix())))},
+		{name: "expired", token: keys.sign(t, rs, with(validClaims(), "exp", float64(testNow.Add(-time.Minute).Unix()))), wantErr: "token expired"},
+		{name: "no exp", token: keys.sign(t, rs, with(validClaims(), "exp", nil)), wantErr: "exp is required"},
+		{name: "exp as string", token: keys.sign(t, rs, with(validClaims(), "exp", "1900000000")), wantErr: "exp is required"},
+		{name: "not yet valid", token: keys.sign(t, rs, with(validClaims(), "nbf", float64(testNow.Add(time.Minute).Unix()))), wantErr: "not yet valid"},
+		{name: "nbf within leeway", token: keys.sign(t, rs, with(validClaims(), "nbf", float64(testNow.Add(20*time.Second).Unix())))},
+		{name: "other issuer", token: keys.sign(t, rs, with(validClaims(), "iss", "https://evil.example.com")), wantErr: "unexpected issuer"},
+		{name: "other audience", token: keys.sign(t, rs, with(validClaims(), "aud", "billing")), wantErr: "unexpected audience"},
+		{name: "no audience", token: keys.sign(t, rs, with(validClaims(), "aud", nil)), wantErr: "unexpected audience"},
+		{name: "sub not uuid", token: keys.sign(t, rs, with(validClaims(), "sub", "alice")), wantErr: "sub must be a user UUID"},
+		{name: "claims tampered", token: parts[0] + "." + segment(t, with(validClaims(), "sub", uuid.NewString())) + "." + parts[2], wantErr: "bad signature"},
+		{name: "signature tampered", token: parts[0] + "." + parts[1] + "." + b64(make([]byte, 256)), wantErr: "bad signature"},
+		{name: "signature stripped", token: parts[0] + "." + parts[1] + ".", wantErr: "bad signature"},
+		{name: "alg none", token: segment(t, map[string]interface{}{"alg": "none"}) + "." + parts[1] + ".", wantErr: "unsupported alg"},
+		{name: "alg confusion", token: confused, wantErr: "no key for alg HS256"},
+		{name: "unknown kid", token: keys.sign(t, map[string]interface{}{"alg": AlgRS256, "kid": "old"}, validClaims()), wantErr: "no key for alg RS256"},
+		{name: "kid of other key", token: keys.sign(t, map[string]interface{}{"alg": AlgES256, "kid": "rs"}, validClaims()), wantErr: "no key for alg ES256"},
+		{name: "two parts", token: parts[0] + "." + parts[1], wantErr: "malformed token"},
+		{name: "bad header", token: "!!!." + parts[1] + "." + parts[2], wantErr: "header"},
+		{name: "bad signature encoding", token: parts[0] + "." + parts[1] + ".!!!", wantErr: "signature encoding"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := j.Authenticate(context.Background(), tc.token)
+			if tc.wantErr != "" {
+				if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Authenticate error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id.Subject != testUser {
+				t.Fatalf("subject = %v, want %v", id.Subject, testUser)
+			}
+		})
+	}
+}
+
+func TestES256RejectsMalformedSignature(t *testing.T) {
+	keys := newTestKeys(t)
+	k := Key{Alg: AlgES256, Key: &keys.ec.PublicKey}
+	token := keys.sign(t, map[string]interface{}{"alg": AlgES256}, validClaims())
+	parts := strings.Split(token, ".")
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	signed := parts[0] + "." + parts[1]
+	if !verifySignature(k, signed, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	for name, bad := range map[string][]byte{
+		"short":  sig[:63],
+		"long":   append(append([]byte{}, sig...), 0),
+		"zero r": append(make([]byte, 32), sig[32:]...),
+	} {
+		if verifySignature(k, signed, bad) {
+			t.Errorf("%s signature accepted", name)
+		}
+	}
+	if verifySignature(Key{Alg: AlgES256, Key: &keys.rsa.PublicKey}, signed, sig) {
+		t.Error("signature accepted with a key of the wrong type")
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaJWK := map[string]string{"kty": "RSA", "kid": "rs", "n": b64(keys.rsa.N.Bytes()), "e": "AQAB"}
+	ecJWK := map[string]string{"kty": "EC", "kid": "es", "crv": "P-256",
+		"x": b64(keys.ec.PublicKey.X.FillBytes(make([]byte, 32))), "y": b64(keys.ec.PublicKey.Y.FillBytes(make([]byte, 32)))}
+	octJWK := map[string]string{"kty": "oct", "kid": "hs", "k": b64(testSecret)}
+	withField := func(k map[string]string, name, value string) map[string]string {
+		out := map[string]string{}
+		for n, v := range k {
+			out[n] = v
+		}
+		out[name] = value
+		return out
+	}
+	cases := []struct {
+		name     string
+		data     []byte
+		wantAlgs []string
+		wantErr  string
+	}{
+		{name: "all kinds", data: jwksJSON(t, rsaJWK, ecJWK, octJWK), wantAlgs: []string{AlgRS256, AlgES256, AlgHS256}},
+		{name: "matching alg", data: jwksJSON(t, withField(rsaJWK, "alg", AlgRS256)), wantAlgs: []string{AlgRS256}},
+		{name: "encryption keys skipped", data: jwksJSON(t, withField(rsaJWK, "use", "enc"), ecJWK), wantAlgs: []string{AlgES256}},
+		{name: "only encryption keys", data: jwksJSON(t, withField(rsaJWK, "use", "enc")), wantErr: "no signing keys"},
+		{name: "empty", data: []byte(`{"keys":[]}`), wantErr: "no signing keys"},
+		{name: "not json", data: []byte(`keys`), wantErr: "jwks"},
+		{name: "alg mismatch", data: jwksJSON(t, withField(rsaJWK, "alg", AlgHS256)), wantErr: "does not match"},
+		{name: "short RSA", data: jwksJSON(t, withField(rsaJWK, "n", b64(small.N.Bytes()))), wantErr: "2048"},
+		{name: "bad RSA exponent", data: jwksJSON(t, withField(rsaJWK, "e", b64(make([]byte, 5)))), wantErr: "invalid RSA"},
+		{name: "other curve", data: jwksJSON(t, withField(ecJWK, "crv", "P-384")), wantErr: "unsupported curve"},
+		{name: "point off curve", data: jwksJSON(t, withField(ecJWK, "y", b64(make([]byte, 32)))), wantErr: "kid \"es\""},
+		{name: "short EC coordinate", data: jwksJSON(t, withField(ecJWK, "x", b64(make([]byte, 31)))), wantErr: "invalid EC"},
+		{name: "short oct", data: jwksJSON(t, withField(octJWK, "k", b64(make([]byte, 16)))), wantErr: "at least 32 bytes"},
+		{name: "unsupported kty", data: jwksJSON(t, map[string]string{"kty": "OKP", "crv": "Ed25519"}), wantErr: "unsupported kty"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseJWKS(tc.data)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseJWKS error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.wantAlgs) {
+				t.Fatalf("keys = %d, want %d", len(got), len(tc.wantAlgs))
+			}
+			for i, alg := range tc.wantAlgs {
+				if got[i].Alg != alg || got[i].ID == "" {
+					t.Fatalf("keys[%d] = %s %q, want %s", i, got[i].Alg, got[i].ID, alg)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKSFileReload(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	write := func(data []byte, mod time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rsaJWK := map[string]string{"kty": "RSA", "kid": "rs-1", "n": b64(keys.rsa.N.Bytes()), "e": "AQAB"}
+	write(jwksJSON(t, rsaJWK), time.Now().Add(-time.Hour))
+	f, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := NewJWT(f, JWTConfig{})
+	token := keys.sign(t, map[string]interface{}{"alg": AlgRS256, "kid": "rs-1"}, with(validClaims(), "exp", float64(time.Now().Add(time.Hour).Unix())))
+	if _, err := j.Authenticate(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Битый файл: остаются прежние ключи.
+	write([]byte(`{"keys":`), time.Now().Add(-30*time.Minute))
+	f.checked = time.Time{}
+	if _, err := j.Authenticate(context.Background(), token); err != nil {
+		t.Fatalf("after broken reload: %v", err)
+	}
+
+	// Ротация у провайдера: старый kid больше не принимается.
+	write(jwksJSON(t, map[string]string{"kty": "oct", "kid": "hs-2", "k": b64(testSecret)}), time.Now())
+	f.checked = time.Time{}
+	if _, err := j.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("after rotation error = %v, want ErrInvalidToken", err)
+	}
+
+	if _, err := LoadJWKSFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("LoadJWKSFile of a missing file succeeded")
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims map[string]interface{}
+		want   bool
+	}{
+		{name: "scope string", claims: map[string]interface{}{"scope": "openid data:publish"}, want: true},
+		{name: "scp list", claims: map[string]interface{}{"scp": []interface{}{"data:publish"}}, want: true},
+		{name: "prefix only", claims: map[string]interface{}{"scope": "data:publisher"}},
+		{name: "other scope", claims: map[string]interface{}{"scope": "data:subscribe"}},
+		{name: "none", claims: map[string]interface{}{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := &Identity{Claims: tc.claims}
+			if got := id.HasScope(ScopePublish); got != tc.want {
+				t.Fatalf("HasScope = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
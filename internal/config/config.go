@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -29,6 +32,115 @@ type Config struct {
 	WSReadBufferSize  int
 	WSWriteBufferSize int
 	WSMaxMessageSize  int64
+
+	StorageDir string // каталог локального хранилища содержимого файлов
+
+	// Storage — бэкенд содержимого: Backend "local" (StorageDir) или "s3".
+	Storage struct {
+		Backend     string
+		S3Endpoint  string
+		S3Region    string
+		S3Bucket    string
+		S3AccessKey string
+		S3SecretKey string
+		S3PathStyle bool
+		// UploadURLTTL — срок ссылок прямой загрузки; UploadPartSize — размер части multipart;
+		// UploadMaxSizeBytes — лимит размера прямой загрузки (FILE_MAX_SIZE_BYTES действует для загрузки через сервис).
+		UploadURLTTL       time.Duration
+		UploadPartSize     int64
+		UploadMaxSizeBytes int64
+	}
+
+	// Files — политика приёма файлов (оба пути UploadFile).
+	Files struct {
+		MaxSizeBytes int64
+		AllowedTypes []string // пусто — разрешено всё, кроме DeniedTypes
+		DeniedTypes  []string // дополняет встроенный список исполняемых типов
+		// GCInterval — период сборки мусора; GCGrace — сколько мягко удалённый файл хранится до освобождения.
+		GCInterval time.Duration
+		GCGrace    time.Duration
+	}
+
+	// Sanitize — удаление EXIF/XMP/IPTC из JPEG/PNG/WebP при загрузке; KeepOriginal — хранить исходник.
+	Sanitize struct {
+		Enabled      bool
+		KeepOriginal bool
+	}
+
+	// Encryption — шифрование содержимого хранилища: мастер-ключи "kid:base64" (Keys) или файл с ними (KeyFile).
+	// Первый ключ — активный. Пусто — содержимое хранится открытым.
+	Encryption struct {
+		Keys    string
+		KeyFile string
+	}
+
+	// Auth — аутентификация по токену доступа. JWT проверяются ключами из JWKSFile и/или HS256Secret,
+	// opaque-токены — через IntrospectionURL. Ничего не задано — аутентификация выключена
+	// и user_id берётся из запроса.
+	Auth struct {
+		JWKSFile                  string
+		HS256Secret               string
+		Issuer                    string
+		Audience                  string
+		ClockSkew                 time.Duration
+		IntrospectionURL          string
+		IntrospectionClientID     string
+		IntrospectionClientSecret string
+		IntrospectionCacheTTL     time.Duration
+	}
+
+	// Membership — источник состава сессий: Source "none" (проверка выключена), "local" (session_members)
+	// или "session-manager" (HTTP-запрос к SessionManagerURL, ответы кэшируются на CacheTTL).
+	Membership struct {
+		Source              string
+		SessionManagerURL   string
+		SessionManagerToken string
+		CacheTTL            time.Duration
+	}
+
+	// FileURL — подпись ссылок на файлы: Keys "kid:secret,..." (первый — активный), TTL по умолчанию и максимум.
+	FileURL struct {
+		Keys   string
+		TTL    time.Duration
+		MaxTTL time.Duration
+	}
+
+	// Quota — лимиты хранилища на сессию и пользователя (0 — без ограничения).
+	Quota struct {
+		SessionBytes int64
+		SessionFiles int64
+		UserBytes    int64
+		UserFiles    int64
+	}
+
+	// Scan — антивирусная проверка загрузок: Scanner "none" или "clamd".
+	Scan struct {
+		Scanner      string
+		ClamdAddress string
+		Timeout      time.Duration
+		Workers      int
+	}
+
+	// RateLimit — лимиты входящих WebSocket-кадров: списки "kind=rate:burst,..." ("*" — остальные виды,
+	// пусто или "none" — без лимита) на подключение, пользователя и сессию; после MaxViolations превышений
+	// за ViolationWindow подключение закрывается (0 — не закрывать).
+	RateLimit struct {
+		Conn            string
+		User            string
+		Session         string
+		MaxViolations   int
+		ViolationWindow time.Duration
+	}
+
+	// AllowedOrigins — источники браузерных запросов для WebSocket и CORS REST ("https://*.example.com", "*").
+	// Пусто: в production — только тот же источник, иначе — любой.
+	AllowedOrigins []string
+
+	// Thumbnails — размеры превью изображений (большая сторона, px); пусто — превью отключены.
+	Thumbnails struct {
+		Sizes   []int
+		Workers int
+	}
 }
 
 func Load() (*Config, error) {
@@ -55,6 +167,62 @@ func Load() (*Config, error) {
 	cfg.DB.Password = getEnv("DB_PASSWORD", "postgres")
 	cfg.DB.Database = getEnv("DB_DATABASE", "data_channel_service")
 	cfg.DB.SSLMode = getEnv("DB_SSLMODE", "disable")
+	cfg.StorageDir = getEnv("STORAGE_DIR", "./data/files")
+	cfg.Storage.Backend = getEnv("STORAGE_BACKEND", "local")
+	cfg.Storage.S3Endpoint = getEnv("S3_ENDPOINT", "")
+	cfg.Storage.S3Region = getEnv("S3_REGION", "us-east-1")
+	cfg.Storage.S3Bucket = getEnv("S3_BUCKET", "")
+	cfg.Storage.S3AccessKey = getEnv("S3_ACCESS_KEY", "")
+	cfg.Storage.S3SecretKey = getEnv("S3_SECRET_KEY", "")
+	cfg.Storage.S3PathStyle, _ = strconv.ParseBool(getEnv("S3_PATH_STYLE", "true"))
+	cfg.Storage.UploadURLTTL, _ = time.ParseDuration(getEnv("UPLOAD_URL_TTL", "1h"))
+	cfg.Storage.UploadPartSize, _ = strconv.ParseInt(getEnv("UPLOAD_PART_SIZE", "67108864"), 10, 64)
+	cfg.Storage.UploadMaxSizeBytes, _ = strconv.ParseInt(getEnv("UPLOAD_MAX_SIZE_BYTES", "5368709120"), 10, 64)
+	cfg.Files.MaxSizeBytes, _ = strconv.ParseInt(getEnv("FILE_MAX_SIZE_BYTES", "52428800"), 10, 64)
+	cfg.Files.AllowedTypes = splitList(getEnv("FILE_ALLOWED_TYPES", ""))
+	cfg.Files.DeniedTypes = splitList(getEnv("FILE_DENIED_TYPES", ""))
+	cfg.Files.GCInterval, _ = time.ParseDuration(getEnv("FILE_GC_INTERVAL", "10m"))
+	cfg.Files.GCGrace, _ = time.ParseDuration(getEnv("FILE_GC_GRACE", "24h"))
+	cfg.Sanitize.Enabled, _ = strconv.ParseBool(getEnv("SANITIZE_METADATA", "false"))
+	cfg.Sanitize.KeepOriginal, _ = strconv.ParseBool(getEnv("SANITIZE_KEEP_ORIGINAL", "false"))
+	for _, v := range splitList(getEnv("THUMBNAIL_SIZES", "128,512")) {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Thumbnails.Sizes = append(cfg.Thumbnails.Sizes, n)
+		}
+	}
+	cfg.Thumbnails.Workers, _ = strconv.Atoi(getEnv("THUMBNAIL_WORKERS", "2"))
+	cfg.Encryption.Keys = getEnv("ENCRYPTION_KEYS", "")
+	cfg.Encryption.KeyFile = getEnv("ENCRYPTION_KEY_FILE", "")
+	cfg.Auth.JWKSFile = getEnv("AUTH_JWKS_FILE", "")
+	cfg.Auth.HS256Secret = getEnv("AUTH_HS256_SECRET", "")
+	cfg.Auth.Issuer = getEnv("AUTH_ISSUER", "")
+	cfg.Auth.Audience = getEnv("AUTH_AUDIENCE", "")
+	cfg.Auth.ClockSkew, _ = time.ParseDuration(getEnv("AUTH_CLOCK_SKEW", "1m"))
+	cfg.Auth.IntrospectionURL = getEnv("AUTH_INTROSPECTION_URL", "")
+	cfg.Auth.IntrospectionClientID = getEnv("AUTH_INTROSPECTION_CLIENT_ID", "")
+	cfg.Auth.IntrospectionClientSecret = getEnv("AUTH_INTROSPECTION_CLIENT_SECRET", "")
+	cfg.Auth.IntrospectionCacheTTL, _ = time.ParseDuration(getEnv("AUTH_INTROSPECTION_CACHE_TTL", "30s"))
+	cfg.Membership.Source = getEnv("MEMBERSHIP_SOURCE", "local")
+	cfg.Membership.SessionManagerURL = getEnv("SESSION_MANAGER_URL", "")
+	cfg.Membership.SessionManagerToken = getEnv("SESSION_MANAGER_TOKEN", "")
+	cfg.Membership.CacheTTL, _ = time.ParseDuration(getEnv("MEMBERSHIP_CACHE_TTL", "30s"))
+	cfg.FileURL.Keys = getEnv("FILE_URL_KEYS", "")
+	cfg.FileURL.TTL, _ = time.ParseDuration(getEnv("FILE_URL_TTL", "15m"))
+	cfg.FileURL.MaxTTL, _ = time.ParseDuration(getEnv("FILE_URL_MAX_TTL", "168h"))
+	cfg.Quota.SessionBytes, _ = strconv.ParseInt(getEnv("QUOTA_SESSION_BYTES", "0"), 10, 64)
+	cfg.Quota.SessionFiles, _ = strconv.ParseInt(getEnv("QUOTA_SESSION_FILES", "0"), 10, 64)
+	cfg.Quota.UserBytes, _ = strconv.ParseInt(getEnv("QUOTA_USER_BYTES", "0"), 10, 64)
+	cfg.Quota.UserFiles, _ = strconv.ParseInt(getEnv("QUOTA_USER_FILES", "0"), 10, 64)
+	cfg.Scan.Scanner = getEnv("SCANNER", "none")
+	cfg.Scan.ClamdAddress = getEnv("CLAMD_ADDRESS", "tcp://localhost:3310")
+	cfg.Scan.Timeout, _ = time.ParseDuration(getEnv("CLAMD_TIMEOUT", "60s"))
+	cfg.Scan.Workers, _ = strconv.Atoi(getEnv("SCAN_WORKERS", "2"))
+	cfg.AllowedOrigins = splitList(getEnv("ALLOWED_ORIGINS", ""))
+	cfg.RateLimit.Conn = getEnv("RATE_LIMIT_CONN", "*=20:40")
+	cfg.RateLimit.User = getEnv("RATE_LIMIT_USER", "")
+	cfg.RateLimit.Session = getEnv("RATE_LIMIT_SESSION", "")
+	cfg.RateLimit.MaxViolations, _ = strconv.Atoi(getEnv("RATE_LIMIT_MAX_VIOLATIONS", "50"))
+	cfg.RateLimit.ViolationWindow, _ = time.ParseDuration(getEnv("RATE_LIMIT_VIOLATION_WINDOW", "10s"))
 	return cfg, nil
 }
 
@@ -62,12 +230,65 @@ func (c *Config) Validate() error {
 	if c.DB.Host == "" || c.DB.Database == "" {
 		return errors.New("config: DB_HOST and DB_DATABASE are required")
 	}
+	if c.Files.MaxSizeBytes <= 0 {
+		return errors.New("config: FILE_MAX_SIZE_BYTES must be positive")
+	}
+	if c.Files.GCInterval <= 0 {
+		return errors.New("config: FILE_GC_INTERVAL must be a positive duration")
+	}
+	if c.Scan.Scanner != "none" && c.Scan.Scanner != "clamd" {
+		return fmt.Errorf("config: unknown SCANNER %q (none, clamd)", c.Scan.Scanner)
+	}
+	switch c.Storage.Backend {
+	case "local":
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" {
+			return errors.New("config: STORAGE_BACKEND=s3 requires S3_ENDPOINT and S3_BUCKET")
+		}
+		if c.Storage.UploadMaxSizeBytes <= 0 || c.Storage.UploadMaxSizeBytes > 5<<30 {
+			return errors.New("config: UPLOAD_MAX_SIZE_BYTES must be between 1 and 5 GiB (S3 CopyObject limit)")
+		}
+	default:
+		return fmt.Errorf("config: unknown STORAGE_BACKEND %q (local, s3)", c.Storage.Backend)
+	}
+	switch c.Membership.Source {
+	case "none", "local":
+	case "session-manager":
+		if c.Membership.SessionManagerURL == "" {
+			return errors.New("config: MEMBERSHIP_SOURCE=session-manager requires SESSION_MANAGER_URL")
+		}
+	default:
+		return fmt.Errorf("config: unknown MEMBERSHIP_SOURCE %q (none, local, session-manager)", c.Membership.Source)
+	}
+	if c.Encryption.Keys != "" && c.Encryption.KeyFile != "" {
+		return errors.New("config: set only one of ENCRYPTION_KEYS and ENCRYPTION_KEY_FILE")
+	}
+	if c.Auth.HS256Secret != "" && len(c.Auth.HS256Secret) < 32 {
+		return errors.New("config: AUTH_HS256_SECRET must be at least 32 bytes")
+	}
+	if c.AppEnv == "production" && !c.AuthEnabled() {
+		return errors.New("config: in production AUTH_JWKS_FILE, AUTH_HS256_SECRET or AUTH_INTROSPECTION_URL is required")
+	}
 	if c.AppEnv == "production" && c.DB.Password == "" {
 		return errors.New("config: in production DB_PASSWORD is required")
 	}
+	if c.AppEnv == "production" && slices.Contains(c.AllowedOrigins, "*") {
+		return errors.New("config: in production ALLOWED_ORIGINS must list origins explicitly, not \"*\"")
+	}
+	if c.AppEnv == "production" && c.FileURL.Keys == "" {
+		return errors.New("config: in production FILE_URL_KEYS is required")
+	}
+	if c.AppEnv == "production" && c.Membership.Source == "none" {
+		return errors.New("config: in production MEMBERSHIP_SOURCE must be local or session-manager")
+	}
 	return nil
 }
 
+// AuthEnabled — задан ли хотя бы один способ проверки токенов.
+func (c *Config) AuthEnabled() bool {
+	return c.Auth.JWKSFile != "" || c.Auth.HS256Secret != "" || c.Auth.IntrospectionURL != ""
+}
+
 func (c *Config) DSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.DB.Host, c.DB.Port, c.DB.User, c.DB.Password, c.DB.Database, c.DB.SSLMode)
@@ -102,3 +323,14 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+// splitList разбирает список через запятую, отбрасывая пустые элементы.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Package envelope реализует шифрование содержимого хранилища конвертным методом:
+// каждый объект шифруется своим случайным ключом данных (DEK, AES-256-GCM), а DEK —
+// мастер-ключом (KEK) из KeyManager. Обёрнутые DEK хранятся отдельно от объектов,
+// поэтому смена мастер-ключа (Rewrap) не переписывает содержимое.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keyLen — длина мастер-ключей и ключей данных (AES-256).
+const keyLen = 32
+
+var (
+	// ErrUnknownKey — DEK обёрнут мастер-ключом, которого нет в KeyManager.
+	ErrUnknownKey = errors.New("envelope: unknown master key")
+	// ErrDecrypt — ключ или содержимое повреждены либо подменены.
+	ErrDecrypt = errors.New("envelope: decryption failed")
+)
+
+// KeyManager оборачивает ключи данных мастер-ключом (локальный файл, внешний KMS).
+type KeyManager interface {
+	// ActiveKeyID — мастер-ключ, которым оборачиваются новые DEK.
+	ActiveKeyID() string
+	Wrap(dek []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyManager хранит мастер-ключи в памяти; первый ключ — активный,
+// остальные используются только для разворачивания до завершения ротации.
+type LocalKeyManager struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// ParseKeys разбирает мастер-ключи "kid:base64key" через запятую или перевод строки
+// (строки, начинающиеся с #, — комментарии). Ключ — 32 байта в стандартном base64.
+func ParseKeys(spec string) (*LocalKeyManager, error) {
+	m := &LocalKeyManager{aeads: map[string]cipher.AEAD{}}
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+		id, enc, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("master key %q: expected kid:base64key", item)
+		}
+		if _, dup := m.aeads[id]; dup {
+			return nil, fmt.Errorf("master key %q: duplicate kid", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(enc))
+		if err != nil || len(key) != keyLen {
+			return nil, fmt.Errorf("master key %q: must be %d bytes in base64", id, keyLen)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		m.aeads[id] = aead
+		if m.active == "" {
+			m.active = id
+		}
+	}
+	if m.active == "" {
+		return nil, errors.New("master key: no keys")
+	}
+	return m, nil
+}
+
+// LoadKeyFile читает мастер-ключи из файла в формате ParseKeys.
+func LoadKeyFile(path string) (*LocalKeyManager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("master key file: %w", err)
+	}
+	return ParseKeys(string(data))
+}
+
+func (m *LocalKeyManager) ActiveKeyID() string { return m.active }
+
+// Wrap шифрует dek активным мастер-ключом; результат — nonce || ciphertext.
+func (m *LocalKeyManager) Wrap(dek []byte) (string, []byte, error) {
+	aead := m.aeads[m.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(dek)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return m.active, aead.Seal(nonce, nonce, dek, []byte(m.active)), nil
+}
+
+func (m *LocalKeyManager) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := m.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	dek, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return dek, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
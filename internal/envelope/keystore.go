@@ -0,0 +1,86 @@
+package envelope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const rewrapBatchSize = 500
+
+// DBKeyStore хранит обёрнутые ключи данных в таблице blob_keys.
+type DBKeyStore struct {
+	db *gorm.DB
+}
+
+func NewDBKeyStore(db *gorm.DB) *DBKeyStore {
+	return &DBKeyStore{db: db}
+}
+
+func (s *DBKeyStore) SaveKey(ctx context.Context, objectKey, ref string, k WrappedKey) error {
+	row := model.BlobKey{ObjectKey: objectKey, Ref: ref, KeyID: k.KeyID, WrappedKey: k.Wrapped}
+	return s.db.WithContext(ctx).Create(&row).Error
+}
+
+func (s *DBKeyStore) LoadKey(ctx context.Context, objectKey, ref string) (WrappedKey, error) {
+	var row model.BlobKey
+	err := s.db.WithContext(ctx).Where("object_key = ? AND ref = ?", objectKey, ref).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return WrappedKey{}, ErrNoKey
+	}
+	if err != nil {
+		return WrappedKey{}, err
+	}
+	return WrappedKey{KeyID: row.KeyID, Wrapped: row.WrappedKey}, nil
+}
+
+func (s *DBKeyStore) DeleteKey(ctx context.Context, objectKey, ref string) error {
+	return s.db.WithContext(ctx).Delete(&model.BlobKey{}, "object_key = ? AND ref = ?", objectKey, ref).Error
+}
+
+func (s *DBKeyStore) DeleteKeys(ctx context.Context, objectKey string) error {
+	return s.db.WithContext(ctx).Delete(&model.BlobKey{}, "object_key = ?", objectKey).Error
+}
+
+// Rewrap переоборачивает активным мастер-ключом все ключи данных, обёрнутые другими ключами.
+// Содержимое объектов не перечитывается. Возвращает число обновлённых ключей; после успешного
+// завершения старые мастер-ключи можно убрать из конфигурации.
+func (s *DBKeyStore) Rewrap(ctx context.Context, km KeyManager) (int, error) {
+	active := km.ActiveKeyID()
+	total := 0
+	for {
+		n := 0
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var rows []model.BlobKey
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("key_id <> ?", active).Limit(rewrapBatchSize).Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				dek, err := km.Unwrap(row.KeyID, row.WrappedKey)
+				if err != nil {
+					return fmt.Errorf("%s: %w", row.ObjectKey, err)
+				}
+				kid, wrapped, err := km.Wrap(dek)
+				if err != nil {
+					return fmt.Errorf("%s: %w", row.ObjectKey, err)
+				}
+				if err := tx.Model(&model.BlobKey{}).Where("object_key = ? AND ref = ?", row.ObjectKey, row.Ref).
+					Updates(map[string]interface{}{"key_id": kid, "wrapped_key": wrapped, "updated_at": time.Now()}).Error; err != nil {
+					return err
+				}
+			}
+			n = len(rows)
+			return nil
+		})
+		total += n
+		if err != nil || n < rewrapBatchSize {
+			return total, err
+		}
+	}
+}
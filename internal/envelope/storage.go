@@ -0,0 +1,153 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+)
+
+// WrappedKey — ключ данных объекта, обёрнутый мастер-ключом KeyID.
+type WrappedKey struct {
+	KeyID   string
+	Wrapped []byte
+}
+
+// ErrNoKey — для объекта нет ключа данных (записан до включения шифрования).
+var ErrNoKey = errors.New("envelope: no data key")
+
+// KeyStore хранит обёрнутые ключи данных отдельно от объектов. Каждая запись объекта получает
+// свой ключ со ссылкой ref из заголовка объекта ("" — объекты формата DCE1).
+type KeyStore interface {
+	SaveKey(ctx context.Context, objectKey, ref string, k WrappedKey) error
+	LoadKey(ctx context.Context, objectKey, ref string) (WrappedKey, error) // ErrNoKey, если ключа нет
+	DeleteKey(ctx context.Context, objectKey, ref string) error
+	// DeleteKeys удаляет все ключи объекта, в том числе ключи перезаписанных версий.
+	DeleteKeys(ctx context.Context, objectKey string) error
+}
+
+// Storage прозрачно шифрует объекты вложенного хранилища при записи и расшифровывает при чтении.
+// Объекты без ключа данных (записанные до включения шифрования) читаются как есть.
+type Storage struct {
+	inner storage.Storage
+	keys  KeyManager
+	store KeyStore
+}
+
+// NewStorage оборачивает inner шифрованием с мастер-ключами km и ключами данных в ks.
+func NewStorage(inner storage.Storage, km KeyManager, ks KeyStore) *Storage {
+	return &Storage{inner: inner, keys: km, store: ks}
+}
+
+// Put шифрует объект новым ключом данных. Ключ сохраняется до записи объекта под случайной ссылкой,
+// которая пишется в заголовок объекта: параллельные записи того же объекта не перетирают ключи друг
+// друга, и какая бы версия ни осталась, её ключ на месте. Ключи перезаписанных версий удаляет Delete.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader) error {
+	dek := make([]byte, keyLen)
+	if _, err := rand.Read(dek); err != nil {
+		return err
+	}
+	rawRef := make([]byte, refLen)
+	if _, err := rand.Read(rawRef); err != nil {
+		return err
+	}
+	ref := hex.EncodeToString(rawRef)
+	kid, wrapped, err := s.keys.Wrap(dek)
+	if err != nil {
+		return fmt.Errorf("wrap data key: %w", err)
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		return err
+	}
+	enc, err := newEncryptReader(aead, r, []byte(key), rawRef)
+	if err != nil {
+		return err
+	}
+	if err := s.store.SaveKey(ctx, key, ref, WrappedKey{KeyID: kid, Wrapped: wrapped}); err != nil {
+		return fmt.Errorf("save data key: %w", err)
+	}
+	if err := s.inner.Put(ctx, key, enc); err != nil {
+		// Объект этой записи не сохранён — её ключ не нужен.
+		if derr := s.store.DeleteKey(context.WithoutCancel(ctx), key, ref); derr != nil {
+			log.Printf("envelope: %s: delete unused data key: %v", key, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Open читает ссылку на ключ из заголовка объекта и расшифровывает его; объект без ключа
+// (записанный до включения шифрования) возвращается как есть.
+func (s *Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	rc, err := s.inner.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(rc, segmentSize)
+	head, _ := br.Peek(len(magic) + refLen)
+	var ref string
+	if len(head) == len(magic)+refLen && bytes.HasPrefix(head, magic) {
+		ref = hex.EncodeToString(head[len(magic):])
+	}
+	wk, err := s.store.LoadKey(ctx, key, ref)
+	if errors.Is(err, ErrNoKey) {
+		return readCloser{br, rc}, nil
+	}
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("load data key: %w", err)
+	}
+	dek, err := s.keys.Unwrap(wk.KeyID, wk.Wrapped)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("unwrap data key for %s: %w", key, err)
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	skip := len(magic) + refLen
+	if ref == "" {
+		skip = len(magicV1)
+		if !bytes.HasPrefix(head, magicV1) {
+			rc.Close()
+			return nil, fmt.Errorf("%s: %w", key, ErrDecrypt)
+		}
+	}
+	if _, err := br.Discard(skip); err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("%s: %w", key, ErrDecrypt)
+	}
+	dr, err := newDecryptReader(aead, br, rc, []byte(key))
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return dr, nil
+}
+
+func (s *Storage) Exists(ctx context.Context, key string) (bool, error) {
+	return s.inner.Exists(ctx, key)
+}
+
+// Delete удаляет объект, затем его ключи данных (crypto-shredding: без ключа копии объекта не прочитать).
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := s.inner.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.store.DeleteKeys(ctx, key)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
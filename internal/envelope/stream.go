@@ -0,0 +1,168 @@
+package envelope
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Формат объекта: magic (4 байта) || ссылка на ключ данных (16 байт) || префикс nonce (7 байт) || сегменты.
+// Сегмент — до segmentSize байт открытого текста, зашифрованных AES-GCM с nonce
+// префикс || номер сегмента (uint32) || признак последнего сегмента; AAD — ключ объекта.
+// Признак последнего сегмента защищает от усечения, номер — от перестановки сегментов.
+// Объекты прежнего формата (magicV1) ссылки не содержат: их ключ хранится с пустой ссылкой.
+const (
+	segmentSize = 64 << 10
+	prefixLen   = 7
+	refLen      = 16
+)
+
+var (
+	magic   = []byte("DCE2")
+	magicV1 = []byte("DCE1")
+)
+
+func segmentNonce(prefix []byte, n uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[prefixLen:], n)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptReader отдаёт зашифрованное представление src по мере чтения.
+type encryptReader struct {
+	aead   cipher.AEAD
+	src    *bufio.Reader
+	aad    []byte
+	prefix []byte
+	n      uint32
+	plain  []byte
+	out    []byte
+	done   bool
+}
+
+func newEncryptReader(aead cipher.AEAD, src io.Reader, aad, ref []byte) (*encryptReader, error) {
+	prefix := make([]byte, prefixLen)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	header := append(append(append([]byte{}, magic...), ref...), prefix...)
+	return &encryptReader{
+		aead:   aead,
+		src:    bufio.NewReaderSize(src, segmentSize),
+		aad:    aad,
+		prefix: prefix,
+		plain:  make([]byte, segmentSize),
+		out:    header,
+	}, nil
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encryptReader) seal() error {
+	n, err := io.ReadFull(e.src, e.plain)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	last := n < segmentSize
+	if !last {
+		if _, perr := e.src.Peek(1); perr == io.EOF {
+			last = true
+		} else if perr != nil {
+			return perr
+		}
+	}
+	if e.n == ^uint32(0) {
+		return errors.New("envelope: object too large")
+	}
+	e.out = e.aead.Seal(e.out[:0], segmentNonce(e.prefix, e.n, last), e.plain[:n], e.aad)
+	e.n++
+	e.done = last
+	return nil
+}
+
+// decryptReader расшифровывает объект в формате encryptReader.
+type decryptReader struct {
+	aead   cipher.AEAD
+	src    *bufio.Reader
+	closer io.Closer
+	aad    []byte
+	prefix []byte
+	n      uint32
+	buf    []byte
+	out    []byte
+	done   bool
+}
+
+// newDecryptReader читает объект из src, уже прочитанного до префикса nonce (magic и ссылка разобраны в Open).
+func newDecryptReader(aead cipher.AEAD, src *bufio.Reader, closer io.Closer, aad []byte) (*decryptReader, error) {
+	prefix := make([]byte, prefixLen)
+	if _, err := io.ReadFull(src, prefix); err != nil {
+		return nil, ErrDecrypt
+	}
+	return &decryptReader{
+		aead:   aead,
+		src:    src,
+		closer: closer,
+		aad:    aad,
+		prefix: prefix,
+		buf:    make([]byte, segmentSize+aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.src, d.buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	last := n < len(d.buf)
+	if !last {
+		if _, perr := d.src.Peek(1); perr == io.EOF {
+			last = true
+		} else if perr != nil {
+			return perr
+		}
+	}
+	plain, err := d.aead.Open(d.buf[:0], segmentNonce(d.prefix, d.n, last), d.buf[:n], d.aad)
+	if err != nil {
+		return ErrDecrypt
+	}
+	d.out = plain
+	d.n++
+	d.done = last
+	return nil
+}
+
+func (d *decryptReader) Close() error { return d.closer.Close() }
@@ -0,0 +1,351 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+)
+
+// memStorage — хранилище объектов в памяти; содержимое можно подменить напрямую.
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	failPut bool
+}
+
+func newMemStorage() *memStorage { return &memStorage{objects: map[string][]byte{}} }
+
+func (m *memStorage) Put(_ context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m.failPut {
+		return errors.New("put failed")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memStorage) Exists(_ context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.objects[key]
+	return ok, nil
+}
+
+func (m *memStorage) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+// memKeyStore — KeyStore в памяти.
+type memKeyStore struct {
+	mu   sync.Mutex
+	keys map[[2]string]WrappedKey
+}
+
+func newMemKeyStore() *memKeyStore { return &memKeyStore{keys: map[[2]string]WrappedKey{}} }
+
+func (m *memKeyStore) SaveKey(_ context.Context, objectKey, ref string, k WrappedKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[[2]string{objectKey, ref}] = k
+	return nil
+}
+
+func (m *memKeyStore) LoadKey(_ context.Context, objectKey, ref string) (WrappedKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[[2]string{objectKey, ref}]
+	if !ok {
+		return WrappedKey{}, ErrNoKey
+	}
+	return k, nil
+}
+
+func (m *memKeyStore) DeleteKey(_ context.Context, objectKey, ref string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, [2]string{objectKey, ref})
+	return nil
+}
+
+func (m *memKeyStore) DeleteKeys(_ context.Context, objectKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k := range m.keys {
+		if k[0] == objectKey {
+			delete(m.keys, k)
+		}
+	}
+	return nil
+}
+
+func masterKey(t *testing.T, id string) string {
+	t.Helper()
+	key := make([]byte, keyLen)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestStorage(t *testing.T, spec string) (*Storage, *memStorage, *memKeyStore) {
+	t.Helper()
+	km, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, ks := newMemStorage(), newMemKeyStore()
+	return NewStorage(inner, km, ks), inner, ks
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func readObject(s *Storage, key string) ([]byte, error) {
+	rc, err := s.Open(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func TestRoundTrip(t *testing.T) {
+	s, inner, _ := newTestStorage(t, masterKey(t, "k1"))
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3 * segmentSize, 3*segmentSize + 17} {
+		plain := randomBytes(t, size)
+		if err := s.Put(context.Background(), "obj", bytes.NewReader(plain)); err != nil {
+			t.Fatalf("size %d: Put: %v", size, err)
+		}
+		if size > 0 && bytes.Contains(inner.objects["obj"], plain[:min(size, 64)]) {
+			t.Fatalf("size %d: plaintext stored", size)
+		}
+		got, err := readObject(s, "obj")
+		if err != nil {
+			t.Fatalf("size %d: read: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: round trip mismatch (%d bytes)", size, len(got))
+		}
+	}
+}
+
+func TestTamperedObjectsAreRejected(t *testing.T) {
+	const (
+		header  = 4 + refLen + prefixLen
+		sealed  = segmentSize + 16 // сегмент с тегом GCM
+		objSize = 2*segmentSize + 100
+	)
+	segment := func(obj []byte, i int) []byte {
+		end := min(header+(i+1)*sealed, len(obj))
+		return obj[header+i*sealed : end]
+	}
+	join := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+	cases := []struct {
+		name   string
+		tamper func(obj []byte) []byte
+	}{
+		{name: "truncated at segment boundary", tamper: func(obj []byte) []byte {
+			return obj[:header+2*sealed]
+		}},
+		{name: "truncated to first segment", tamper: func(obj []byte) []byte {
+			return obj[:header+sealed]
+		}},
+		{name: "truncated inside segment", tamper: func(obj []byte) []byte {
+			return obj[:len(obj)-10]
+		}},
+		{name: "header only", tamper: func(obj []byte) []byte {
+			return obj[:header]
+		}},
+		{name: "cut before nonce prefix", tamper: func(obj []byte) []byte {
+			return obj[:header-2]
+		}},
+		{name: "segments reordered", tamper: func(obj []byte) []byte {
+			return join(obj[:header], segment(obj, 1), segment(obj, 0), segment(obj, 2))
+		}},
+		{name: "segment duplicated", tamper: func(obj []byte) []byte {
+			return join(obj[:header], segment(obj, 0), segment(obj, 0), segment(obj, 1), segment(obj, 2))
+		}},
+		{name: "segment dropped", tamper: func(obj []byte) []byte {
+			return join(obj[:header], segment(obj, 0), segment(obj, 2))
+		}},
+		{name: "data after last segment", tamper: func(obj []byte) []byte {
+			return join(obj, []byte("x"))
+		}},
+		{name: "ciphertext bit flipped", tamper: func(obj []byte) []byte {
+			obj[header+sealed+5] ^= 1
+			return obj
+		}},
+		{name: "nonce prefix changed", tamper: func(obj []byte) []byte {
+			obj[header-1] ^= 1
+			return obj
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, inner, _ := newTestStorage(t, masterKey(t, "k1"))
+			plain := randomBytes(t, objSize)
+			if err := s.Put(context.Background(), "obj", bytes.NewReader(plain)); err != nil {
+				t.Fatal(err)
+			}
+			inner.objects["obj"] = tc.tamper(append([]byte{}, inner.objects["obj"]...))
+			got, err := readObject(s, "obj")
+			if !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("read error = %v (%d bytes), want ErrDecrypt", err, len(got))
+			}
+		})
+	}
+}
+
+func TestObjectBoundToKey(t *testing.T) {
+	s, inner, ks := newTestStorage(t, masterKey(t, "k1"))
+	if err := s.Put(context.Background(), "a", strings.NewReader("secret")); err != nil {
+		t.Fatal(err)
+	}
+	// Объект и его ключ данных скопированы под другой ключ объекта: AAD не совпадает.
+	inner.objects["b"] = inner.objects["a"]
+	for k, v := range ks.keys {
+		if k[0] == "a" {
+			ks.keys[[2]string{"b", k[1]}] = v
+		}
+	}
+	if _, err := readObject(s, "b"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("read copied object error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, newKey := masterKey(t, "old"), masterKey(t, "new")
+	s, inner, ks := newTestStorage(t, oldKey)
+	if err := s.Put(context.Background(), "obj", strings.NewReader("payload")); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := ParseKeys(newKey + "\n# старый ключ до завершения Rewrap\n" + oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readObject(NewStorage(inner, rotated, ks), "obj"); err != nil || string(got) != "payload" {
+		t.Fatalf("read after rotation = %q, %v", got, err)
+	}
+
+	retired, err := ParseKeys(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readObject(NewStorage(inner, retired, ks), "obj"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("read with retired key error = %v, want ErrUnknownKey", err)
+	}
+
+	for k, wk := range ks.keys {
+		wk.Wrapped[len(wk.Wrapped)-1] ^= 1
+		ks.keys[k] = wk
+	}
+	if _, err := readObject(s, "obj"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("read with tampered data key error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestUnencryptedObjectsReadAsIs(t *testing.T) {
+	s, inner, _ := newTestStorage(t, masterKey(t, "k1"))
+	inner.objects["legacy"] = []byte("written before encryption")
+	got, err := readObject(s, "legacy")
+	if err != nil || string(got) != "written before encryption" {
+		t.Fatalf("read legacy object = %q, %v", got, err)
+	}
+}
+
+func TestDataKeyLifecycle(t *testing.T) {
+	s, inner, ks := newTestStorage(t, masterKey(t, "k1"))
+	ctx := context.Background()
+
+	inner.failPut = true
+	if err := s.Put(ctx, "obj", strings.NewReader("x")); err == nil {
+		t.Fatal("Put succeeded with failing storage")
+	}
+	if len(ks.keys) != 0 {
+		t.Fatalf("data keys after failed Put = %d, want 0", len(ks.keys))
+	}
+
+	inner.failPut = false
+	for range 2 {
+		if err := s.Put(ctx, "obj", strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ks.keys) != 2 {
+		t.Fatalf("data keys after overwrite = %d, want 2", len(ks.keys))
+	}
+	if err := s.Delete(ctx, "obj"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ks.keys) != 0 {
+		t.Fatalf("data keys after Delete = %d, want 0", len(ks.keys))
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	valid := masterKey(t, "k1")
+	cases := []struct {
+		name       string
+		spec       string
+		wantActive string
+		wantErr    string
+	}{
+		{name: "first key active", spec: masterKey(t, "k2") + "," + valid, wantActive: "k2"},
+		{name: "comments and newlines", spec: "# ключи\n" + valid + "\n", wantActive: "k1"},
+		{name: "empty", spec: "", wantErr: "no keys"},
+		{name: "no separator", spec: "k1", wantErr: "expected kid:base64key"},
+		{name: "short key", spec: "k1:" + base64.StdEncoding.EncodeToString(make([]byte, 16)), wantErr: "must be 32 bytes"},
+		{name: "not base64", spec: "k1:!!!", wantErr: "must be 32 bytes"},
+		{name: "duplicate kid", spec: valid + "," + valid, wantErr: "duplicate kid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			km, err := ParseKeys(tc.spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseKeys error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if km.ActiveKeyID() != tc.wantActive {
+				t.Fatalf("active = %q, want %q", km.ActiveKeyID(), tc.wantActive)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthInterceptor проверяет токен из метаданных "authorization: Bearer <token>" и кладёт
+// пользователя в context. Сервис reflection доступен без токена. a == nil — проверка выключена.
+func UnaryAuthInterceptor(a auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor — то же для потоковых методов.
+func StreamAuthInterceptor(a auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
+
+func authenticate(ctx context.Context, a auth.Authenticator, method string) (context.Context, error) {
+	if a == nil || strings.HasPrefix(method, "/grpc.reflection.") {
+		return ctx, nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if t, ok := auth.BearerToken(v); ok {
+				token = t
+				break
+			}
+		}
+	}
+	id, err := a.Authenticate(ctx, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) || errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		log.Printf("grpc: auth: %v", err)
+		return nil, status.Error(codes.Unavailable, "authentication unavailable")
+	}
+	return auth.NewContext(ctx, id), nil
+}
+
+// callerID возвращает пользователя, от имени которого выполняется вызов: при аутентификации —
+// субъект токена (supplied должен совпадать с ним или быть пустым), без неё — supplied из запроса.
+// REST-запросы проходят ту же проверку: HTTP-слой кладёт пользователя в context до grpc-gateway.
+func callerID(ctx context.Context, supplied string) (uuid.UUID, error) {
+	if id, ok := auth.FromContext(ctx); ok {
+		if supplied != "" && supplied != id.Subject.String() {
+			return uuid.Nil, status.Error(codes.PermissionDenied, "user_id does not match authenticated user")
+		}
+		return id.Subject, nil
+	}
+	userID, err := uuid.Parse(supplied)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+	return userID, nil
+}
+
+// optionalCallerID — как callerID, но без аутентификации пустой supplied даёт nil.
+func optionalCallerID(ctx context.Context, supplied string) (*uuid.UUID, error) {
+	if _, ok := auth.FromContext(ctx); !ok && supplied == "" {
+		return nil, nil
+	}
+	id, err := callerID(ctx, supplied)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Connect — участие в сессии по gRPC, как по WebSocket: первое сообщение — join, после него поток
+// регистрируется в DataHub (членство, роль, ban и mute, presence — как у WebSocket). Кадры клиента
+// проходят Relay (лимиты, viewer, mute, запись в историю); в ответ — кадры сессии с номером сообщения.
+// Кадр больше MaxFrameBytes или не JSON завершает поток (RESOURCE_EXHAUSTED или INVALID_ARGUMENT).
+// Закрытие сервером (kick, ban, новое подключение того же пользователя) — сообщение closed, затем конец потока.
+func (s *Server) Connect(stream data_channel_service.DataChannelService_ConnectServer) error {
+	if s.Hub == nil {
+		return status.Error(codes.Unimplemented, "connect is not available")
+	}
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	join := first.GetJoin()
+	if join == nil {
+		return status.Error(codes.InvalidArgument, "first message must be join")
+	}
+	sessionID, err := uuid.Parse(join.GetSessionId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	userID, err := callerID(ctx, join.GetUserId())
+	if err != nil {
+		return err
+	}
+	role, err := s.Data.MemberRole(ctx, sessionID, userID)
+	if err != nil {
+		return s.mapError(err)
+	}
+	mutedUntil, err := s.Data.JoinRestrictions(ctx, sessionID, userID)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	c := s.Hub.Register(sessionID, userID, role, nil)
+	defer s.Hub.Unregister(c)
+	c.MuteUntil(mutedUntil)
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			frame := req.GetFrame()
+			if frame == nil {
+				recvErr <- status.Error(codes.InvalidArgument, "expected frame")
+				return
+			}
+			payload := frame.GetPayload()
+			if s.MaxFrameBytes > 0 && int64(len(payload)) > s.MaxFrameBytes {
+				recvErr <- status.Errorf(codes.ResourceExhausted, "frame exceeds %d bytes", s.MaxFrameBytes)
+				return
+			}
+			if !json.Valid(payload) {
+				recvErr <- status.Error(codes.InvalidArgument, "frame payload must be JSON")
+				return
+			}
+			if !s.Hub.HandleFrame(c, payload, s.Data.PersistFrame) {
+				return // закрыто за нарушения лимитов: причина придёт через Done
+			}
+		}
+	}()
+
+	for {
+		select {
+		case f, ok := <-c.Frames():
+			if !ok {
+				return sendClosed(stream, service.CloseInfo{Code: service.CloseReplaced, Reason: "connected elsewhere"})
+			}
+			if err := sendFrame(stream, f); err != nil {
+				return err
+			}
+		case <-c.Done():
+			// Сначала — уже поставленные в очередь кадры (в том числе moderation.*).
+			for _, f := range c.Pending() {
+				if err := sendFrame(stream, f); err != nil {
+					return err
+				}
+			}
+			return sendClosed(stream, c.CloseInfo())
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func sendFrame(stream data_channel_service.DataChannelService_ConnectServer, f service.Frame) error {
+	return stream.Send(&data_channel_service.ConnectResponse{
+		Msg: &data_channel_service.ConnectResponse_Frame{Frame: &data_channel_service.DataFrame{Payload: f.Data, Seq: f.Seq}},
+	})
+}
+
+func sendClosed(stream data_channel_service.DataChannelService_ConnectServer, info service.CloseInfo) error {
+	return stream.Send(&data_channel_service.ConnectResponse{
+		Msg: &data_channel_service.ConnectResponse_Closed{Closed: &data_channel_service.ConnectClosed{Code: int32(info.Code), Reason: info.Reason}},
+	})
+}
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toProtoFileInfo — метаданные файла со ссылкой на содержимое (подписанной, если задан FILE_URL_KEYS).
+func (s *Server) toProtoFileInfo(f *model.ChannelFile) *data_channel_service.FileInfo {
+	url, expires := s.Data.FileURL(f.ID, 0, nil)
+	info := &data_channel_service.FileInfo{
+		Id:               f.ID.String(),
+		SessionId:        f.SessionID.String(),
+		UserId:           f.UserID.String(),
+		Filename:         f.Filename,
+		ContentType:      f.ContentType,
+		SizeBytes:        f.SizeBytes,
+		Sha256:           f.SHA256,
+		ScanStatus:       f.ScanStatus,
+		PreviewStatus:    f.PreviewStatus,
+		Url:              url,
+		CreatedAt:        timestamppb.New(f.CreatedAt),
+		MetadataStripped: f.MetadataStripped,
+	}
+	if !expires.IsZero() {
+		info.UrlExpiresAt = timestamppb.New(expires)
+	}
+	for _, size := range service.ParsePreviewSizes(f.PreviewSizes) {
+		info.PreviewSizes = append(info.PreviewSizes, int32(size))
+	}
+	return info
+}
+
+func (s *Server) ListFiles(ctx context.Context, req *data_channel_service.ListFilesRequest) (*data_channel_service.ListFilesResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	filter := service.FileFilter{ContentType: req.GetContentType(), ScanStatus: req.GetScanStatus()}
+	if req.GetUserId() != "" {
+		userID, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+		}
+		filter.UserID = &userID
+	}
+	// user_id — фильтр по загрузившему; без аутентификации он же считается вызывающим.
+	caller, err := optionalCallerID(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if caller == nil {
+		caller = filter.UserID
+	}
+	if caller == nil {
+		return nil, status.Error(codes.Unauthenticated, "caller is unknown")
+	}
+	if err := s.Data.CheckMember(ctx, sessionID, caller); err != nil {
+		return nil, s.mapError(err)
+	}
+	files, next, err := s.Data.ListFiles(ctx, sessionID, filter, req.GetCursor(), int(req.GetLimit()))
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &data_channel_service.ListFilesResponse{NextCursor: next}
+	for i := range files {
+		resp.Files = append(resp.Files, s.toProtoFileInfo(&files[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetFile(ctx context.Context, req *data_channel_service.GetFileRequest) (*data_channel_service.FileInfo, error) {
+	fileID, err := uuid.Parse(req.GetFileId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Data.CheckFileAccess(ctx, fileID, &userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	f, err := s.Data.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return s.toProtoFileInfo(f), nil
+}
+
+func (s *Server) DeleteFile(ctx context.Context, req *data_channel_service.DeleteFileRequest) (*data_channel_service.DeleteFileResponse, error) {
+	fileID, err := uuid.Parse(req.GetFileId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Data.DeleteFile(ctx, fileID, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &data_channel_service.DeleteFileResponse{}, nil
+}
+
+func (s *Server) GetStorageUsage(ctx context.Context, req *data_channel_service.GetStorageUsageRequest) (*data_channel_service.GetStorageUsageResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	userID, err := optionalCallerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Data.CheckMember(ctx, sessionID, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	usage, err := s.Data.GetUsage(ctx, sessionID, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &data_channel_service.GetStorageUsageResponse{}
+	for _, u := range usage {
+		resp.Usage = append(resp.Usage, &data_channel_service.StorageUsage{
+			Scope:      u.Scope,
+			ScopeId:    u.ScopeID.String(),
+			BytesUsed:  u.BytesUsed,
+			FilesCount: u.FilesCount,
+			BytesLimit: u.BytesLimit,
+			FilesLimit: u.FilesLimit,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetFileURL(ctx context.Context, req *data_channel_service.GetFileURLRequest) (*data_channel_service.GetFileURLResponse, error) {
+	fileID, err := uuid.Parse(req.GetFileId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file_id")
+	}
+	if req.GetTtlSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds must not be negative")
+	}
+	userID, err := optionalCallerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	url, expires, err := s.Data.IssueFileURL(ctx, fileID, time.Duration(req.GetTtlSeconds())*time.Second, userID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &data_channel_service.GetFileURLResponse{Url: url}
+	if !expires.IsZero() {
+		resp.ExpiresAt = timestamppb.New(expires)
+	}
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoMember(m *model.SessionMember) *data_channel_service.Member {
+	out := &data_channel_service.Member{
+		SessionId: m.SessionID.String(),
+		UserId:    m.UserID.String(),
+		Role:      m.Role,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+	}
+	if m.AddedBy != nil {
+		out.AddedBy = m.AddedBy.String()
+	}
+	return out
+}
+
+// trustedMembers — вызывающий — сервис со scope data:members: он меняет состав любой сессии.
+func trustedMembers(ctx context.Context) bool {
+	id, ok := auth.FromContext(ctx)
+	return ok && id.HasScope(auth.ScopeMembers)
+}
+
+func (s *Server) AddMember(ctx context.Context, req *data_channel_service.AddMemberRequest) (*data_channel_service.Member, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	actorID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	memberID, err := uuid.Parse(req.GetMemberId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid member_id")
+	}
+	m, err := s.Data.AddMember(ctx, sessionID, actorID, memberID, req.GetRole(), trustedMembers(ctx))
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toProtoMember(m), nil
+}
+
+func (s *Server) RemoveMember(ctx context.Context, req *data_channel_service.RemoveMemberRequest) (*data_channel_service.RemoveMemberResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	actorID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	memberID, err := uuid.Parse(req.GetMemberId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid member_id")
+	}
+	if err := s.Data.RemoveMember(ctx, sessionID, actorID, memberID, trustedMembers(ctx)); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &data_channel_service.RemoveMemberResponse{}, nil
+}
+
+func (s *Server) ListMembers(ctx context.Context, req *data_channel_service.ListMembersRequest) (*data_channel_service.ListMembersResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	actorID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.Data.ListMembers(ctx, sessionID, actorID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &data_channel_service.ListMembersResponse{}
+	for i := range members {
+		resp.Members = append(resp.Members, toProtoMember(&members[i]))
+	}
+	return resp, nil
+}
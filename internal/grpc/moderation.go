@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// moderationTarget разбирает общие поля запросов модерации: сессию, модератора и участника.
+func moderationTarget(ctx context.Context, sessionIDStr, userIDStr, participantIDStr string) (sessionID, actorID, targetID uuid.UUID, err error) {
+	if sessionID, err = uuid.Parse(sessionIDStr); err != nil {
+		return sessionID, actorID, targetID, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	if actorID, err = callerID(ctx, userIDStr); err != nil {
+		return sessionID, actorID, targetID, err
+	}
+	if targetID, err = uuid.Parse(participantIDStr); err != nil {
+		return sessionID, actorID, targetID, status.Error(codes.InvalidArgument, "invalid participant_id")
+	}
+	return sessionID, actorID, targetID, nil
+}
+
+func toModerationResponse(a *model.ModerationAction) *data_channel_service.ModerationResponse {
+	resp := &data_channel_service.ModerationResponse{ActionId: a.ID.String()}
+	if a.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(*a.ExpiresAt)
+	}
+	return resp
+}
+
+func (s *Server) KickParticipant(ctx context.Context, req *data_channel_service.KickParticipantRequest) (*data_channel_service.ModerationResponse, error) {
+	sessionID, actorID, targetID, err := moderationTarget(ctx, req.GetSessionId(), req.GetUserId(), req.GetParticipantId())
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.Data.KickParticipant(ctx, sessionID, actorID, targetID, req.GetReason())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toModerationResponse(a), nil
+}
+
+func (s *Server) MuteParticipant(ctx context.Context, req *data_channel_service.MuteParticipantRequest) (*data_channel_service.ModerationResponse, error) {
+	sessionID, actorID, targetID, err := moderationTarget(ctx, req.GetSessionId(), req.GetUserId(), req.GetParticipantId())
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.Data.MuteParticipant(ctx, sessionID, actorID, targetID, time.Duration(req.GetDurationSeconds())*time.Second, req.GetReason())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toModerationResponse(a), nil
+}
+
+func (s *Server) BanParticipant(ctx context.Context, req *data_channel_service.BanParticipantRequest) (*data_channel_service.ModerationResponse, error) {
+	sessionID, actorID, targetID, err := moderationTarget(ctx, req.GetSessionId(), req.GetUserId(), req.GetParticipantId())
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.Data.BanParticipant(ctx, sessionID, actorID, targetID, time.Duration(req.GetDurationSeconds())*time.Second, req.GetReason())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toModerationResponse(a), nil
+}
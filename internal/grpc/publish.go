@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// systemSender — значение sender_id для публикации от имени системы.
+const systemSender = "system"
+
+// PublishMessage публикует сообщение сервиса или бота в сессию. От имени системы и чужих отправителей
+// публикуют вызывающие со scope data:publish (без аутентификации — любые); остальные — только от своего
+// имени и по правилам участника.
+func (s *Server) PublishMessage(ctx context.Context, req *data_channel_service.PublishMessageRequest) (*data_channel_service.PublishMessageResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	if req.GetPayload() == nil {
+		return nil, status.Error(codes.InvalidArgument, "payload is required")
+	}
+	payload, err := protojson.Marshal(req.GetPayload())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid payload")
+	}
+	id, authenticated := auth.FromContext(ctx)
+	trusted := !authenticated || id.HasScope(auth.ScopePublish)
+
+	var senderID uuid.UUID
+	switch sender := req.GetSenderId(); {
+	case sender == systemSender:
+		if !trusted {
+			return nil, status.Error(codes.PermissionDenied, "publishing as system requires scope "+auth.ScopePublish)
+		}
+		senderID = service.SystemSenderID
+	case trusted && sender != "":
+		if senderID, err = uuid.Parse(sender); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid sender_id")
+		}
+	default:
+		if !authenticated && sender == "" {
+			return nil, status.Error(codes.InvalidArgument, "sender_id is required")
+		}
+		if senderID, err = callerID(ctx, sender); err != nil {
+			return nil, err
+		}
+	}
+
+	msg, duplicate, err := s.Data.PublishMessage(ctx, service.PublishRequest{
+		SessionID:      sessionID,
+		SenderID:       senderID,
+		Kind:           req.GetKind(),
+		Payload:        payload,
+		IdempotencyKey: req.GetIdempotencyKey(),
+		Trusted:        trusted,
+	})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return &data_channel_service.PublishMessageResponse{Message: toProtoDataMessage(msg), Duplicate: duplicate}, nil
+}
@@ -1,20 +1,27 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"log"
 
 	"github.com/google/uuid"
 	"github.com/psds-microservice/data-channel-service/internal/model"
 	"github.com/psds-microservice/data-channel-service/internal/service"
 	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Deps — зависимости gRPC-сервера (D: зависимость от абстракций).
 type Deps struct {
 	Data service.DataServicer
+	Hub  *service.DataHub // nil — Connect недоступен
+	// MaxFrameBytes — предельный размер кадра Connect, как у WebSocket (WS_MAX_MESSAGE_SIZE); 0 — без ограничения.
+	MaxFrameBytes int64
 }
 
 // Server implements data_channel_service.DataChannelServiceServer
@@ -23,6 +30,26 @@ type Server struct {
 	Deps
 }
 
+// ReasonQuotaExceeded — ErrorInfo.Reason ошибки RESOURCE_EXHAUSTED из-за квоты хранилища
+// (в отличие от отставшей подписки и лимитов частоты).
+const ReasonQuotaExceeded = "QUOTA_EXCEEDED"
+
+const errorDomain = "data-channel-service"
+
+// IsQuotaExceeded сообщает, что ошибка gRPC вызвана превышением квоты хранилища.
+func IsQuotaExceeded(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == ReasonQuotaExceeded {
+			return true
+		}
+	}
+	return false
+}
+
 // NewServer создаёт gRPC-сервер с внедрёнными сервисами
 func NewServer(deps Deps) *Server {
 	return &Server{Deps: deps}
@@ -32,6 +59,39 @@ func (s *Server) mapError(err error) error {
 	if err == nil {
 		return nil
 	}
+	switch {
+	case errors.Is(err, service.ErrInvalidFile),
+		errors.Is(err, service.ErrFileTooLarge),
+		errors.Is(err, service.ErrFileTypeNotAllowed),
+		errors.Is(err, service.ErrContentTypeMismatch),
+		errors.Is(err, service.ErrChecksumMismatch):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrFileNotFound), errors.Is(err, service.ErrUploadSessionNotFound),
+		errors.Is(err, service.ErrMemberNotFound), errors.Is(err, service.ErrMessageNotFound),
+		errors.Is(err, service.ErrSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrFileNotScanned), errors.Is(err, service.ErrUploadSessionClosed),
+		errors.Is(err, service.ErrDirectUploadUnsupported), errors.Is(err, service.ErrMembershipExternal),
+		errors.Is(err, service.ErrSessionClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrFileQuarantined), errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidCursor), errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrInvalidDuration), errors.Is(err, service.ErrInvalidMessage),
+		errors.Is(err, service.ErrInvalidSession):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrSessionExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrQuotaExceeded):
+		st, derr := status.New(codes.ResourceExhausted, err.Error()).
+			WithDetails(&errdetails.ErrorInfo{Reason: ReasonQuotaExceeded, Domain: errorDomain})
+		if derr != nil {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, service.ErrSubscriptionLagging):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
 	log.Printf("grpc: error: %v", err)
 	return status.Error(codes.Internal, err.Error())
 }
@@ -45,6 +105,7 @@ func toProtoDataMessage(msg *model.ChannelMessage) *data_channel_service.DataMes
 		SenderId: msg.UserID.String(),
 		Content:  string(msg.Payload),
 		Type:     msg.Kind,
+		Seq:      msg.Seq,
 	}
 }
 
@@ -53,6 +114,13 @@ func (s *Server) GetHistory(ctx context.Context, req *data_channel_service.GetHi
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
 	}
+	userID, err := optionalCallerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Data.CheckMember(ctx, sessionID, userID); err != nil {
+		return nil, s.mapError(err)
+	}
 	limit := int(req.GetLimit())
 	if limit <= 0 {
 		limit = 100
@@ -75,9 +143,9 @@ func (s *Server) UploadFile(ctx context.Context, req *data_channel_service.Uploa
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
 	}
-	userID, err := uuid.Parse(req.GetUserId())
+	userID, err := callerID(ctx, req.GetUserId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+		return nil, err
 	}
 	if req.GetFilename() == "" {
 		return nil, status.Error(codes.InvalidArgument, "filename is required")
@@ -85,14 +153,41 @@ func (s *Server) UploadFile(ctx context.Context, req *data_channel_service.Uploa
 	if len(req.GetContent()) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "content is required")
 	}
-	// Сохраняем файл (в реальности нужно сохранить в storage и вернуть путь)
-	// Пока используем пустой storagePath
-	file, err := s.Data.SaveFile(sessionID, userID, req.GetFilename(), "application/octet-stream", int64(len(req.GetContent())), "")
+	file, err := s.Data.UploadFile(ctx, service.Upload{
+		SessionID:    sessionID,
+		UserID:       userID,
+		Filename:     req.GetFilename(),
+		DeclaredType: req.GetContentType(),
+		Size:         int64(len(req.GetContent())),
+		Content:      bytes.NewReader(req.GetContent()),
+	})
 	if err != nil {
 		return nil, s.mapError(err)
 	}
-	return &data_channel_service.UploadFileResponse{
-		FileId: file.ID.String(),
-		Url:    "/data/file/" + file.ID.String(),
-	}, nil
+	url, expires := s.Data.FileURL(file.ID, 0, nil)
+	resp := &data_channel_service.UploadFileResponse{
+		FileId:      file.ID.String(),
+		Url:         url,
+		ContentType: file.ContentType,
+		Sha256:      file.SHA256,
+	}
+	if !expires.IsZero() {
+		resp.UrlExpiresAt = timestamppb.New(expires)
+	}
+	return resp, nil
+}
+
+func (s *Server) DeleteMessage(ctx context.Context, req *data_channel_service.DeleteMessageRequest) (*data_channel_service.DeleteMessageResponse, error) {
+	messageID, err := uuid.Parse(req.GetMessageId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid message_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Data.DeleteMessage(ctx, messageID, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	return &data_channel_service.DeleteMessageResponse{}, nil
 }
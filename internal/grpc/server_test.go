@@ -0,0 +1,38 @@
+package grpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestQuotaErrorsAreDistinguishable(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		wantCode  codes.Code
+		wantQuota bool
+	}{
+		{name: "quota", err: fmt.Errorf("%w: session limit 10 files", service.ErrQuotaExceeded), wantCode: codes.ResourceExhausted, wantQuota: true},
+		{name: "lagging subscription", err: service.ErrSubscriptionLagging, wantCode: codes.ResourceExhausted},
+		{name: "file too large", err: service.ErrFileTooLarge, wantCode: codes.InvalidArgument},
+		{name: "plain rate limit", err: status.Error(codes.ResourceExhausted, "rate limited"), wantCode: codes.ResourceExhausted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.err
+			if _, ok := status.FromError(err); !ok {
+				err = (&Server{}).mapError(err)
+			}
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("code = %v, want %v", got, tc.wantCode)
+			}
+			if got := IsQuotaExceeded(err); got != tc.wantQuota {
+				t.Fatalf("IsQuotaExceeded = %v, want %v", got, tc.wantQuota)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CreateSession(ctx context.Context, req *data_channel_service.CreateSessionRequest) (*data_channel_service.Session, error) {
+	var sessionID uuid.UUID
+	if req.GetSessionId() != "" {
+		var err error
+		if sessionID, err = uuid.Parse(req.GetSessionId()); err != nil || sessionID == uuid.Nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+		}
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	var metadata []byte
+	if req.GetMetadata() != nil {
+		if metadata, err = protojson.Marshal(req.GetMetadata()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid metadata")
+		}
+	}
+	sess, err := s.Data.CreateSession(ctx, service.SessionInput{
+		ID:        sessionID,
+		Title:     req.GetTitle(),
+		Metadata:  metadata,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toProtoSession(sess), nil
+}
+
+func (s *Server) GetSession(ctx context.Context, req *data_channel_service.GetSessionRequest) (*data_channel_service.Session, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	userID, err := optionalCallerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Data.CheckMember(ctx, sessionID, userID); err != nil {
+		return nil, s.mapError(err)
+	}
+	sess, err := s.Data.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toProtoSession(sess), nil
+}
+
+func (s *Server) CloseSession(ctx context.Context, req *data_channel_service.CloseSessionRequest) (*data_channel_service.Session, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.Data.CloseSession(ctx, sessionID, userID, req.GetReason())
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return toProtoSession(sess), nil
+}
+
+func toProtoSession(sess *model.Session) *data_channel_service.Session {
+	out := &data_channel_service.Session{
+		SessionId:   sess.ID.String(),
+		Title:       sess.Title,
+		Status:      sess.Status,
+		CloseReason: sess.CloseReason,
+		CreatedAt:   timestamppb.New(sess.CreatedAt),
+		UpdatedAt:   timestamppb.New(sess.UpdatedAt),
+	}
+	if len(sess.Metadata) > 0 {
+		md := &structpb.Struct{}
+		if protojson.Unmarshal(sess.Metadata, md) == nil {
+			out.Metadata = md
+		}
+	}
+	if sess.CreatedBy != nil {
+		out.CreatedBy = sess.CreatedBy.String()
+	}
+	if sess.ClosedBy != nil {
+		out.ClosedBy = sess.ClosedBy.String()
+	}
+	if sess.ClosedAt != nil {
+		out.ClosedAt = timestamppb.New(*sess.ClosedAt)
+	}
+	return out
+}
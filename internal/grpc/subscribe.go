@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const subscribeBacklogPage = 500
+
+// Subscribe передаёт кадры сессии (или всех сессий) наблюдателю, не подключая его к сессии: без presence
+// и без адресованных участникам кадров. С after_seq (0 — с начала сессии) сначала досылается история после
+// него; живые кадры, уже отправленные из истории, пропускаются. Без after_seq — только живые кадры. Доступен токенам со scope data:subscribe (без аутентификации — всем).
+func (s *Server) Subscribe(req *data_channel_service.SubscribeRequest, stream data_channel_service.DataChannelService_SubscribeServer) error {
+	if s.Hub == nil {
+		return status.Error(codes.Unimplemented, "subscribe is not available")
+	}
+	ctx := stream.Context()
+	if id, ok := auth.FromContext(ctx); ok && !id.HasScope(auth.ScopeSubscribe) {
+		return status.Error(codes.PermissionDenied, "subscribe requires scope "+auth.ScopeSubscribe)
+	}
+	var sessionID uuid.UUID
+	if sid := req.GetSessionId(); sid != "" && sid != "*" {
+		var err error
+		if sessionID, err = uuid.Parse(sid); err != nil || sessionID == uuid.Nil {
+			return status.Error(codes.InvalidArgument, "invalid session_id")
+		}
+	}
+	if req.GetAfterSeq() < 0 {
+		return status.Error(codes.InvalidArgument, "after_seq must not be negative")
+	}
+
+	// Подписка до чтения истории: кадры, сохранённые во время досылки, уже в очереди.
+	sub := s.Hub.Subscribe(sessionID, req.GetKinds())
+	defer s.Hub.Unsubscribe(sub)
+
+	backlogSeq := req.GetAfterSeq()
+	if req.AfterSeq != nil {
+		filter := service.MessageFilter{SessionID: sessionID, Kinds: req.GetKinds()}
+		for {
+			page, err := s.Data.MessagesMatching(ctx, filter, backlogSeq, subscribeBacklogPage)
+			if err != nil {
+				return s.mapError(err)
+			}
+			for _, m := range page {
+				err := stream.Send(&data_channel_service.SubscribeEvent{
+					SessionId: m.SessionID.String(),
+					Kind:      m.Kind,
+					Seq:       m.Seq,
+					Payload:   m.Payload,
+				})
+				if err != nil {
+					return err
+				}
+				backlogSeq = m.Seq
+			}
+			if len(page) < subscribeBacklogPage {
+				break
+			}
+		}
+	}
+
+	send := func(f service.SessionFrame) error {
+		if f.Seq > 0 && f.Seq <= backlogSeq {
+			return nil
+		}
+		return stream.Send(&data_channel_service.SubscribeEvent{
+			SessionId: f.SessionID.String(),
+			Kind:      f.Kind,
+			Seq:       f.Seq,
+			Payload:   f.Data,
+		})
+	}
+	for {
+		select {
+		case f := <-sub.Frames():
+			if err := send(f); err != nil {
+				return err
+			}
+		case <-sub.Done():
+			// Кадры до переполнения доставляются, затем подписчик продолжает с последнего seq.
+			for n := len(sub.Frames()); n > 0; n-- {
+				if err := send(<-sub.Frames()); err != nil {
+					return err
+				}
+			}
+			return s.mapError(service.ErrSubscriptionLagging)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
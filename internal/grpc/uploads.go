@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+	"github.com/psds-microservice/data-channel-service/pkg/gen/data_channel_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CreateUploadSession(ctx context.Context, req *data_channel_service.CreateUploadSessionRequest) (*data_channel_service.CreateUploadSessionResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	up, err := s.Data.CreateUploadSession(ctx, service.DirectUploadRequest{
+		SessionID:   sessionID,
+		UserID:      userID,
+		Filename:    req.GetFilename(),
+		ContentType: req.GetContentType(),
+		Size:        req.GetSizeBytes(),
+		SHA256:      req.GetSha256(),
+	})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	resp := &data_channel_service.CreateUploadSessionResponse{
+		UploadId:  up.Session.ID.String(),
+		Url:       up.URL,
+		Headers:   up.Headers,
+		ExpiresAt: timestamppb.New(up.Session.ExpiresAt),
+	}
+	for _, p := range up.Parts {
+		resp.Parts = append(resp.Parts, &data_channel_service.UploadPart{
+			PartNumber: int32(p.Number),
+			Url:        p.URL,
+			SizeBytes:  p.Size,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) CompleteUpload(ctx context.Context, req *data_channel_service.CompleteUploadRequest) (*data_channel_service.FileInfo, error) {
+	uploadID, err := uuid.Parse(req.GetUploadId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid upload_id")
+	}
+	userID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]storage.Part, 0, len(req.GetParts()))
+	for _, p := range req.GetParts() {
+		if p.GetPartNumber() <= 0 || p.GetEtag() == "" {
+			return nil, status.Error(codes.InvalidArgument, "each part needs part_number and etag")
+		}
+		parts = append(parts, storage.Part{Number: int(p.GetPartNumber()), ETag: p.GetEtag()})
+	}
+	f, err := s.Data.CompleteUpload(ctx, uploadID, userID, parts)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	return s.toProtoFileInfo(f), nil
+}
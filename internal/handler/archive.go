@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+// SessionArchive обрабатывает GET /data/{session_id}/files.zip: ZIP со всеми файлами сессии
+// и manifest.json, собираемый на лету без промежуточного файла. Доступен только участникам сессии.
+func SessionArchive(dataSvc *service.DataService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err := uuid.Parse(r.PathValue("session_id"))
+		if err != nil {
+			http.Error(w, "invalid session_id", http.StatusBadRequest)
+			return
+		}
+		if err := dataSvc.CheckMember(r.Context(), sessionID, requestUserID(r)); err != nil {
+			if errors.Is(err, service.ErrForbidden) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			log.Printf("archive %s: membership: %v", sessionID, err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		// Архив может передаваться дольше WriteTimeout сервера: снимаем дедлайн для этого ответа.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("archive %s: write deadline: %v", sessionID, err)
+		}
+		h := w.Header()
+		h.Set("Content-Type", "application/zip")
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "session-" + sessionID.String() + ".zip"}))
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Cache-Control", "no-store")
+		cw := &countingWriter{w: w}
+		if _, err := dataSvc.WriteArchive(r.Context(), cw, sessionID); err != nil {
+			log.Printf("archive %s: %v", sessionID, err)
+			if cw.n == 0 {
+				h.Del("Content-Disposition")
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			// После начала передачи статус уже не изменить: обрываем соединение, чтобы клиент не принял неполный архив.
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+)
+
+// RequireAuth пропускает к next только запросы с действительным токеном (см. auth.TokenFromRequest)
+// и кладёт пользователя в context. a == nil — аутентификация выключена, запросы проходят как есть.
+func RequireAuth(a auth.Authenticator, next http.Handler) http.Handler {
+	return authenticate(a, next, false)
+}
+
+// OptionalAuth проверяет токен, только если он передан: без токена запрос проходит анонимно
+// (для ссылок, где доступ подтверждает подпись), с недействительным — отклоняется.
+func OptionalAuth(a auth.Authenticator, next http.Handler) http.Handler {
+	return authenticate(a, next, true)
+}
+
+func authenticate(a auth.Authenticator, next http.Handler, optional bool) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// С включённой аутентификацией пользователь определяется только токеном.
+		r.Header.Del("X-User-ID")
+		token := auth.TokenFromRequest(r)
+		if token == "" && optional {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := a.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrNoToken) || errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			log.Printf("auth: %v", err)
+			http.Error(w, "authentication unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), id)))
+	})
+}
+
+// requestUserID — пользователь запроса: субъект токена, а без аутентификации — заголовок X-User-ID,
+// проставляемый шлюзом; nil, если неизвестен.
+func requestUserID(r *http.Request) *uuid.UUID {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		return &id.Subject
+	}
+	id, err := uuid.Parse(r.Header.Get("X-User-ID"))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// callerID возвращает пользователя запроса: при аутентификации — субъект токена (переданный supplied
+// должен с ним совпадать или быть пустым), без неё — supplied из запроса.
+func callerID(r *http.Request, supplied string) (uuid.UUID, error) {
+	if id, ok := auth.FromContext(r.Context()); ok {
+		if supplied != "" && supplied != id.Subject.String() {
+			return uuid.Nil, errUserMismatch
+		}
+		return id.Subject, nil
+	}
+	return uuid.Parse(supplied)
+}
+
+var errUserMismatch = errors.New("user_id does not match authenticated user")
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+// DownloadFile обрабатывает GET /data/file/{id}: отдаёт содержимое файла по подписанной ссылке
+// (exp, uid, kid, sig — см. GetFileURL; та же подпись действует для превью).
+// SHA-256 содержимого передаётся в ETag, Digest (RFC 3230) и X-Checksum-SHA256 для проверки целостности.
+func DownloadFile(dataSvc *service.DataService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fileID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid file id", http.StatusBadRequest)
+			return
+		}
+		if !authorizeFile(w, r, dataSvc, fileID) {
+			return
+		}
+		f, content, err := dataSvc.OpenFile(r.Context(), fileID)
+		if errors.Is(err, service.ErrFileNotFound) {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+		if status, ok := scanErrorStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if err != nil {
+			log.Printf("download %s: %v", fileID, err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		defer content.Close()
+
+		h := w.Header()
+		if f.SHA256 != "" {
+			etag := `"` + f.SHA256 + `"`
+			h.Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			if sum, err := hex.DecodeString(f.SHA256); err == nil {
+				h.Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+			}
+			h.Set("X-Checksum-SHA256", f.SHA256)
+		}
+		h.Set("Content-Type", f.ContentType)
+		h.Set("Content-Length", strconv.FormatInt(f.SizeBytes, 10))
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Filename}))
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Cache-Control", "private, max-age=0")
+		if r.Method == http.MethodHead {
+			return
+		}
+		if _, err := io.Copy(w, content); err != nil {
+			log.Printf("download %s: %v", fileID, err)
+		}
+	}
+}
+
+// Thumbnail обрабатывает GET /data/file/{id}/thumbnail?size=N: превью изображения
+// ближайшего размера не меньше N (без size — наименьшее).
+func Thumbnail(dataSvc *service.DataService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fileID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid file id", http.StatusBadRequest)
+			return
+		}
+		size := 0
+		if v := r.URL.Query().Get("size"); v != "" {
+			if size, err = strconv.Atoi(v); err != nil || size <= 0 {
+				http.Error(w, "invalid size", http.StatusBadRequest)
+				return
+			}
+		}
+		if !authorizeFile(w, r, dataSvc, fileID) {
+			return
+		}
+		content, contentType, actual, err := dataSvc.OpenThumbnail(r.Context(), fileID, size)
+		if status, ok := scanErrorStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrFileNotFound), errors.Is(err, service.ErrPreviewUnavailable):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, service.ErrPreviewNotReady):
+			w.Header().Set("Retry-After", "2")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			log.Printf("thumbnail %s: %v", fileID, err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		defer content.Close()
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Thumbnail-Size", strconv.Itoa(actual))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+		if _, err := io.Copy(w, content); err != nil {
+			log.Printf("thumbnail %s: %v", fileID, err)
+		}
+	}
+}
+
+// authorizeFile проверяет подпись ссылки и членство пользователя в сессии файла; при отказе пишет ответ.
+// Анонимный запрос допускается только по ссылке с проверенной подписью: её выдача уже проверена (IssueFileURL).
+func authorizeFile(w http.ResponseWriter, r *http.Request, dataSvc *service.DataService, fileID uuid.UUID) bool {
+	caller := requestUserID(r)
+	signed, err := dataSvc.VerifyFileURL(fileID, r.URL.Query(), caller)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	if signed && caller == nil {
+		return true
+	}
+	err = dataSvc.CheckFileAccess(r.Context(), fileID, caller)
+	switch {
+	case errors.Is(err, service.ErrFileNotFound):
+		http.Error(w, "file not found", http.StatusNotFound)
+		return false
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	case err != nil:
+		log.Printf("download %s: membership: %v", fileID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// scanErrorStatus: файл на проверке — 409, в карантине — 403.
+func scanErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, service.ErrFileNotScanned):
+		return http.StatusConflict, true
+	case errors.Is(err, service.ErrFileQuarantined):
+		return http.StatusForbidden, true
+	}
+	return 0, false
+}
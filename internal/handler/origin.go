@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OriginPolicy — список разрешённых источников (Origin) браузерных запросов. Шаблон — "https://app.example.com",
+// "https://*.example.com" (любой поддомен, но не сам домен) или без схемы ("*.example.com" — http и https);
+// порт, если указан, должен совпадать. "*" разрешает любой источник. Запросы без Origin (не из браузера)
+// и с Origin, совпадающим с Host запроса, разрешены всегда.
+type OriginPolicy struct {
+	any      bool
+	patterns []originPattern
+}
+
+type originPattern struct {
+	scheme string // "" — http или https
+	host   string // без "*."
+	port   string
+	sub    bool // шаблон "*.host"
+}
+
+// NewOriginPolicy разбирает шаблоны источников.
+func NewOriginPolicy(patterns []string) (*OriginPolicy, error) {
+	p := &OriginPolicy{}
+	for _, raw := range patterns {
+		s := strings.ToLower(strings.TrimSpace(raw))
+		if s == "*" {
+			p.any = true
+			continue
+		}
+		var op originPattern
+		if scheme, rest, ok := strings.Cut(s, "://"); ok {
+			if scheme != "http" && scheme != "https" {
+				return nil, fmt.Errorf("origin %q: scheme must be http or https", raw)
+			}
+			op.scheme, s = scheme, rest
+		}
+		if strings.ContainsAny(s, "/?#@") {
+			return nil, fmt.Errorf("origin %q: expected scheme://host[:port]", raw)
+		}
+		op.host, op.sub = strings.CutPrefix(s, "*.")
+		if i := strings.LastIndex(op.host, ":"); i >= 0 {
+			op.host, op.port = op.host[:i], op.host[i+1:]
+		}
+		if op.host == "" || strings.Contains(op.host, "*") {
+			return nil, fmt.Errorf("origin %q: wildcard is allowed only as the leftmost label", raw)
+		}
+		p.patterns = append(p.patterns, op)
+	}
+	return p, nil
+}
+
+// AllowAny — разрешены ли любые источники.
+func (p *OriginPolicy) AllowAny() bool { return p.any }
+
+// Allowed сообщает, можно ли принять запрос r с его заголовком Origin.
+func (p *OriginPolicy) Allowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || p.any {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return p.match(strings.ToLower(u.Scheme), strings.ToLower(u.Hostname()), u.Port())
+}
+
+func (p *OriginPolicy) match(scheme, host, port string) bool {
+	for _, op := range p.patterns {
+		if op.scheme != "" && op.scheme != scheme || op.scheme == "" && scheme != "http" && scheme != "https" {
+			continue
+		}
+		if op.port != port {
+			continue
+		}
+		if op.sub && strings.HasSuffix(host, "."+op.host) || !op.sub && host == op.host {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrigin — Allowed с записью отклонённого источника в журнал.
+func (p *OriginPolicy) checkOrigin(r *http.Request) bool {
+	if p.Allowed(r) {
+		return true
+	}
+	log.Printf("origin: rejected %q for %s %s from %s", r.Header.Get("Origin"), r.Method, r.URL.Path, r.RemoteAddr)
+	return false
+}
+
+// corsExposedHeaders — заголовки ответов, которые скрипт другого источника может прочитать.
+const corsExposedHeaders = "Content-Disposition, ETag, Digest, X-Checksum-SHA256"
+
+// CORS отвечает на preflight-запросы и добавляет заголовки CORS для разрешённых источников.
+// Запросы из неразрешённых источников отклоняются (403) до next, чтобы чужая страница не могла
+// выполнить изменяющий запрос, даже не читая ответ.
+func CORS(p *OriginPolicy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !p.checkOrigin(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-User-ID")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
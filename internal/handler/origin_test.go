@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewOriginPolicyRejectsBadPatterns(t *testing.T) {
+	cases := []struct {
+		pattern string
+		wantErr string
+	}{
+		{pattern: "ftp://example.com", wantErr: "scheme must be http or https"},
+		{pattern: "https://example.com/app", wantErr: "expected scheme://host[:port]"},
+		{pattern: "https://user@example.com", wantErr: "expected scheme://host[:port]"},
+		{pattern: "https://example.com?x=1", wantErr: "expected scheme://host[:port]"},
+		{pattern: "https://app.*.example.com", wantErr: "leftmost label"},
+		{pattern: "https://*example.com", wantErr: "leftmost label"},
+		{pattern: "*.", wantErr: "leftmost label"},
+		{pattern: "https://", wantErr: "leftmost label"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			_, err := NewOriginPolicy([]string{tc.pattern})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("NewOriginPolicy(%q) error = %v, want %q", tc.pattern, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOriginPolicyAllowed(t *testing.T) {
+	p, err := NewOriginPolicy([]string{
+		"https://app.example.com",
+		"https://*.example.org",
+		"*.example.net",
+		"http://localhost:3000",
+		" HTTPS://Admin.Example.com ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		origin string
+		host   string
+		want   bool
+	}{
+		{name: "no origin", origin: "", want: true},
+		{name: "exact", origin: "https://app.example.com", want: true},
+		{name: "case insensitive", origin: "https://APP.example.com", want: true},
+		{name: "normalized pattern", origin: "https://admin.example.com", want: true},
+		{name: "other scheme", origin: "http://app.example.com"},
+		{name: "other port", origin: "https://app.example.com:8443"},
+		{name: "sibling", origin: "https://evil.example.com"},
+		{name: "suffix attack", origin: "https://app.example.com.evil.io"},
+		{name: "prefix attack", origin: "https://evilapp.example.com"},
+		{name: "subdomain", origin: "https://a.b.example.org", want: true},
+		{name: "wildcard excludes apex", origin: "https://example.org"},
+		{name: "wildcard label boundary", origin: "https://evilexample.org"},
+		{name: "wildcard keeps scheme", origin: "http://a.example.org"},
+		{name: "schemeless http", origin: "http://a.example.net", want: true},
+		{name: "schemeless https", origin: "https://a.example.net", want: true},
+		{name: "schemeless other scheme", origin: "ws://a.example.net"},
+		{name: "port", origin: "http://localhost:3000", want: true},
+		{name: "missing port", origin: "http://localhost"},
+		{name: "same host", origin: "https://files.internal:8080", host: "files.internal:8080", want: true},
+		{name: "same host other port", origin: "https://files.internal:9090", host: "files.internal:8080"},
+		{name: "null origin", origin: "null"},
+		{name: "garbage", origin: "://"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ws/data/x", nil)
+			r.Host = "api.example.com"
+			if tc.host != "" {
+				r.Host = tc.host
+			}
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if got := p.Allowed(r); got != tc.want {
+				t.Fatalf("Allowed(%q) = %v, want %v", tc.origin, got, tc.want)
+			}
+		})
+	}
+
+	anyOrigin, err := NewOriginPolicy([]string{"*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Origin", "https://anything.io")
+	if !anyOrigin.AllowAny() || !anyOrigin.Allowed(r) {
+		t.Fatal(`"*" does not allow any origin`)
+	}
+	empty, _ := NewOriginPolicy(nil)
+	if empty.Allowed(r) {
+		t.Fatal("empty policy allows a foreign origin")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	p, err := NewOriginPolicy([]string{"https://app.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantNext    bool
+		wantAllowed string
+	}{
+		{name: "no origin", method: http.MethodPost, wantStatus: http.StatusOK, wantNext: true},
+		{name: "allowed", method: http.MethodPost, origin: "https://app.example.com", wantStatus: http.StatusOK, wantNext: true, wantAllowed: "https://app.example.com"},
+		{name: "preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, wantStatus: http.StatusNoContent, wantAllowed: "https://app.example.com"},
+		{name: "foreign post", method: http.MethodPost, origin: "https://evil.io", wantStatus: http.StatusForbidden},
+		{name: "foreign get", method: http.MethodGet, origin: "https://evil.io", wantStatus: http.StatusForbidden},
+		{name: "foreign preflight", method: http.MethodOptions, origin: "https://evil.io", preflight: true, wantStatus: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			h := CORS(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+			r := httptest.NewRequest(tc.method, "/data/s/files", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tc.wantStatus)
+			}
+			if called != tc.wantNext {
+				t.Fatalf("next called = %v, want %v", called, tc.wantNext)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.wantAllowed {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tc.wantAllowed)
+			}
+			if tc.origin != "" && w.Header().Get("Vary") != "Origin" {
+				t.Fatal("response without Vary: Origin")
+			}
+		})
+	}
+}
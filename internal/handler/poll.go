@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+// pollBacklogPage — размер страницы истории при досылке после after_seq.
+const pollBacklogPage = 500
+
+// Poll обрабатывает GET /poll/data/{session_id}?cursor=&timeout=&after_seq=: первый опрос подключает клиента
+// к сессии как участника (членство, ban, presence — как у WebSocket), следующие подтверждают кадры
+// до cursor и ждут новых до timeout секунд (по умолчанию 25, максимум 60). Ответ — service.PollResult;
+// при closed клиент подключается заново опросом без cursor, передав after_seq — последний полученный seq:
+// сначала выдаются сохранённые сообщения после него. Отправка — POST на тот же путь (SendFrame).
+func Poll(pollers *service.Pollers, svc *service.DataService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, userID, ok := sessionCaller(w, r)
+		if !ok {
+			return
+		}
+		var cursor int64
+		if v := r.URL.Query().Get("cursor"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = n
+		}
+		var afterSeq int64
+		if v := r.URL.Query().Get("after_seq"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid after_seq", http.StatusBadRequest)
+				return
+			}
+			afterSeq = n
+		}
+		wait := service.PollDefaultWait
+		if v := r.URL.Query().Get("timeout"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid timeout", http.StatusBadRequest)
+				return
+			}
+			wait = min(time.Duration(n)*time.Second, service.PollMaxWait)
+		}
+		pc := pollers.Lookup(sessionID, userID)
+		if pc == nil {
+			role, mutedUntil, status, err := joinSession(r, svc, sessionID, userID)
+			if err != nil {
+				http.Error(w, err.Error(), status)
+				return
+			}
+			pc = pollers.Join(sessionID, userID, role, mutedUntil)
+			// Регистрация до чтения истории: кадры, сохранённые во время досылки, уже в очереди.
+			for afterSeq > 0 {
+				page, err := svc.MessagesAfter(r.Context(), sessionID, afterSeq, pollBacklogPage)
+				if err != nil {
+					log.Printf("poll %s: backlog: %v", sessionID, err)
+					pollers.Leave(pc)
+					http.Error(w, "internal error", http.StatusInternalServerError)
+					return
+				}
+				pc.Replay(page)
+				if len(page) < pollBacklogPage {
+					break
+				}
+				afterSeq = page[len(page)-1].Seq
+			}
+		}
+		// Ожидание может быть дольше WriteTimeout сервера.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+		res, err := pollers.Poll(r.Context(), pc, cursor, wait)
+		if err != nil {
+			return // клиент ушёл
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(res)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+// SendFrame обрабатывает POST /sse/data/{session_id} и POST /poll/data/{session_id}: тело — кадр JSON
+// (не больше maxBytes), который рассылается участникам сессии и сохраняется так же, как кадр WebSocket.
+// Ответ — {"seq"}.
+// Ошибки: 403 (зритель, mute, не участник, ban), 413, 429 (лимит кадров, Retry-After).
+func SendFrame(hub *service.DataHub, svc *service.DataService, maxBytes int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, userID, ok := sessionCaller(w, r)
+		if !ok {
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+		if !json.Valid(body) {
+			http.Error(w, "body must be JSON", http.StatusBadRequest)
+			return
+		}
+		role, mutedUntil, status, err := joinSession(r, svc, sessionID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		seq, err := hub.Relay(hub.Sender(sessionID, userID, role, mutedUntil), body, svc.PersistFrame)
+		switch {
+		case errors.Is(err, service.ErrRateLimited):
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		case errors.Is(err, service.ErrForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, service.ErrSessionClosed):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int64{"seq": seq})
+	}
+}
+
+// sessionCaller разбирает session_id из пути и пользователя запроса (токен или query user_id).
+func sessionCaller(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(r.PathValue("session_id"))
+	if err != nil {
+		http.Error(w, "invalid session_id", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := callerID(r, r.URL.Query().Get("user_id"))
+	if errors.Is(err, errUserMismatch) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return uuid.Nil, uuid.Nil, false
+	}
+	if err != nil {
+		http.Error(w, "invalid user_id", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return sessionID, userID, true
+}
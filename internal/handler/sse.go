@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/psds-microservice/data-channel-service/internal/service"
+)
+
+const (
+	sseKeepAlive   = 15 * time.Second
+	sseBacklogPage = 500
+)
+
+// SSEStream обрабатывает GET /sse/data/{session_id}: подключает клиента к сессии как участника
+// (членство, ban, presence — как у WebSocket) и отдаёт поток text/event-stream. Каждый кадр — событие
+// message, id — номер сообщения (у presence и error номера нет). С Last-Event-ID (заголовок или query
+// last_event_id) сначала досылаются сохранённые сообщения после него. Закрытие сервером (kick, ban) —
+// событие close с {"code", "reason"}. Без аутентификации пользователь — query user_id.
+func SSEStream(hub *service.DataHub, svc *service.DataService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, userID, ok := sessionCaller(w, r)
+		if !ok {
+			return
+		}
+		var lastSeq int64
+		if v := r.Header.Get("Last-Event-ID"); v != "" || r.URL.Query().Has("last_event_id") {
+			if v == "" {
+				v = r.URL.Query().Get("last_event_id")
+			}
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+				return
+			}
+			lastSeq = n
+		}
+		role, mutedUntil, status, err := joinSession(r, svc, sessionID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		rc := http.NewResponseController(w)
+		// Поток живёт дольше WriteTimeout сервера.
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("sse %s: write deadline: %v", sessionID, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		// Регистрация до чтения истории: кадры, сохранённые во время досылки, уже в очереди;
+		// повторы отсекаются по последнему номеру из истории. Номера сессии фиксируются в базе
+		// по возрастанию, так что всё не больше него в истории уже было; живые кадры после него
+		// пишутся в порядке рассылки, даже если номер меньше предыдущего.
+		client := hub.Register(sessionID, userID, role, nil)
+		defer hub.Unregister(client)
+		client.MuteUntil(mutedUntil)
+
+		backlogSeq := lastSeq
+		if lastSeq > 0 {
+			for {
+				page, err := svc.MessagesAfter(r.Context(), sessionID, backlogSeq, sseBacklogPage)
+				if err != nil {
+					log.Printf("sse %s: backlog: %v", sessionID, err)
+					return
+				}
+				for _, m := range page {
+					if writeSSE(w, "", m.Seq, m.Payload) != nil {
+						return
+					}
+					backlogSeq = m.Seq
+				}
+				if len(page) < sseBacklogPage {
+					break
+				}
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case f, ok := <-client.Frames():
+				if !ok {
+					return // пользователь подключился заново
+				}
+				if f.Seq > 0 && f.Seq <= backlogSeq {
+					continue
+				}
+				if writeSSE(w, "", f.Seq, f.Data) != nil {
+					return
+				}
+			case <-client.Done():
+				// Сначала — уже поставленные в очередь кадры (в том числе moderation.*).
+				for _, f := range client.Pending() {
+					if f.Seq == 0 || f.Seq > backlogSeq {
+						_ = writeSSE(w, "", f.Seq, f.Data)
+					}
+				}
+				info, _ := json.Marshal(client.CloseInfo())
+				_ = writeSSE(w, "close", 0, info)
+				_ = rc.Flush()
+				return
+			case <-keepAlive.C:
+				if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+			if rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE пишет одно событие; многострочные данные разбиваются на строки data:.
+func writeSSE(w io.Writer, event string, id int64, data []byte) error {
+	var b bytes.Buffer
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	if id > 0 {
+		fmt.Fprintf(&b, "id: %d\n", id)
+	}
+	data = bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\r"), []byte("\n"))
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
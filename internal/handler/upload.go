@@ -2,42 +2,46 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/psds-microservice/data-channel-service/internal/service"
 )
 
 const maxMultipartMemory = 32 << 20 // 32 MiB
-const maxFileSizeBytes = 50 << 20   // 50 MiB
-const maxFilenameLen = 255
+const multipartOverhead = 1 << 20   // запас на поля формы и границы multipart
 
-// UploadFileMultipart обрабатывает POST /data/file с multipart/form-data (session_id, user_id, file).
-// Возвращает JSON: {"id": "...", "filename": "...", "url": "..."} для совместимости с тестами и клиентами.
+// UploadFileMultipart обрабатывает POST /data/file с multipart/form-data (session_id, user_id, file);
+// при аутентификации user_id можно не передавать — загрузившим считается владелец токена.
+// Возвращает JSON: {"id", "filename", "content_type", "sha256", "url", "url_expires_at"}; url подписан, если включена подпись ссылок.
 func UploadFileMultipart(dataSvc *service.DataService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		r.Body = http.MaxBytesReader(w, r.Body, dataSvc.Policy().Limit()+multipartOverhead)
 		if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, service.ErrFileTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 			http.Error(w, "invalid multipart form", http.StatusBadRequest)
 			return
 		}
-		sessionIDStr := r.FormValue("session_id")
-		userIDStr := r.FormValue("user_id")
-		if sessionIDStr == "" || userIDStr == "" {
-			http.Error(w, "session_id and user_id required", http.StatusBadRequest)
-			return
-		}
-		sessionID, err := uuid.Parse(sessionIDStr)
+		sessionID, err := uuid.Parse(r.FormValue("session_id"))
 		if err != nil {
 			http.Error(w, "invalid session_id", http.StatusBadRequest)
 			return
 		}
-		userID, err := uuid.Parse(userIDStr)
+		userID, err := callerID(r, r.FormValue("user_id"))
+		if errors.Is(err, errUserMismatch) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			http.Error(w, "invalid user_id", http.StatusBadRequest)
 			return
@@ -48,41 +52,49 @@ func UploadFileMultipart(dataSvc *service.DataService) http.HandlerFunc {
 			return
 		}
 		defer file.Close()
-		if header.Size < 0 || header.Size > maxFileSizeBytes {
-			http.Error(w, "file size exceeds limit or invalid", http.StatusBadRequest)
+		f, err := dataSvc.UploadFile(r.Context(), service.Upload{
+			SessionID:    sessionID,
+			UserID:       userID,
+			Filename:     header.Filename,
+			DeclaredType: header.Header.Get("Content-Type"),
+			Size:         header.Size,
+			Content:      file,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), uploadErrorStatus(err))
 			return
 		}
-		// Path traversal protection: use only base name, no directory components.
-		rawName := header.Filename
-		if rawName == "" {
-			rawName = "file"
-		}
-		filename := filepath.Base(strings.TrimSpace(rawName))
-		if filename == "" || filename == "." || strings.Contains(filename, "..") {
-			filename = "file"
+		url, expires := dataSvc.FileURL(f.ID, 0, nil)
+		resp := map[string]string{
+			"id":           f.ID.String(),
+			"filename":     f.Filename,
+			"content_type": f.ContentType,
+			"sha256":       f.SHA256,
+			"url":          url,
 		}
-		if len(filename) > maxFilenameLen {
-			filename = filename[:maxFilenameLen]
-		}
-		contentType := header.Header.Get("Content-Type")
-		if contentType == "" {
-			contentType = "application/octet-stream"
-		}
-		if err := dataSvc.ValidateFile(filename, header.Size, ""); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		f, err := dataSvc.SaveFile(sessionID, userID, filename, contentType, header.Size, "")
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		if !expires.IsZero() {
+			resp["url_expires_at"] = expires.UTC().Format(time.RFC3339)
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"id":       f.ID.String(),
-			"filename": f.Filename,
-			"url":      "/data/file/" + f.ID.String(),
-		})
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// uploadErrorStatus отображает ошибки конвейера загрузки в HTTP-статусы.
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge), errors.Is(err, service.ErrQuotaExceeded):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrFileTypeNotAllowed), errors.Is(err, service.ErrContentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrInvalidFile), errors.Is(err, service.ErrChecksumMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrSessionClosed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
 	}
 }
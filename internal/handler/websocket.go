@@ -1,50 +1,103 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
 	"github.com/psds-microservice/data-channel-service/internal/service"
-	"gorm.io/datatypes"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  4096,
-	WriteBufferSize: 4096,
-	CheckOrigin:     func(r *http.Request) bool { return true },
-}
-
 type WebSocketHandler struct {
-	Hub *service.DataHub
-	Svc *service.DataService
+	Hub      *service.DataHub
+	Svc      *service.DataService
+	Origins  *OriginPolicy
+	upgrader websocket.Upgrader
 }
 
-func NewWebSocketHandler(hub *service.DataHub, svc *service.DataService) *WebSocketHandler {
-	return &WebSocketHandler{Hub: hub, Svc: svc}
+// NewWebSocketHandler создаёт обработчик; подключения принимаются только из источников origins.
+func NewWebSocketHandler(hub *service.DataHub, svc *service.DataService, origins *OriginPolicy) *WebSocketHandler {
+	h := &WebSocketHandler{Hub: hub, Svc: svc, Origins: origins}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     origins.Allowed,
+	}
+	return h
 }
 
+// ServeWS обрабатывает /ws/data/:session_id[/:user_id]. При аутентификации пользователь — владелец токена
+// (user_id в пути, если указан, должен с ним совпадать); без неё user_id в пути обязателен.
+// Роль участника определяется при подключении (MemberRole) и действует до его конца.
+// Подключение из неразрешённого источника (Origin) отклоняется (403) до проверки членства.
 func (h *WebSocketHandler) ServeWS(c *gin.Context) {
+	if !h.Origins.checkOrigin(c.Request) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+		return
+	}
 	sessionID, err := uuid.Parse(c.Param("session_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id"})
 		return
 	}
-	userID, err := uuid.Parse(c.Param("user_id"))
+	userID, err := callerID(c.Request, c.Param("user_id"))
+	if errors.Is(err, errUserMismatch) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
 		return
 	}
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	role, mutedUntil, status, err := joinSession(c.Request, h.Svc, sessionID, userID)
 	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
-	client := h.Hub.Register(sessionID, userID, conn)
-	defer h.Hub.Unregister(sessionID, userID)
+	// Токен в подпротоколе ("bearer, <token>"): браузер ждёт, что сервер подтвердит один из предложенных.
+	var respHeader http.Header
+	if slices.Contains(websocket.Subprotocols(c.Request), auth.WebSocketProtocol) {
+		respHeader = http.Header{"Sec-WebSocket-Protocol": {auth.WebSocketProtocol}}
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, respHeader)
+	if err != nil {
+		return
+	}
+	client := h.Hub.Register(sessionID, userID, role, service.NewWSTransport(conn))
+	defer h.Hub.Unregister(client)
+	client.MuteUntil(mutedUntil)
 
 	go client.WritePump()
-	client.ReadPump(h.Hub, func(sessionID, userID uuid.UUID, payload []byte) {
-		_ = h.Svc.AppendMessage(sessionID, userID, "data", datatypes.JSON(payload))
-	})
+	client.ReadPump(h.Hub, h.Svc.PersistFrame)
+}
+
+// joinSession проверяет подключение пользователя к сессии (членство, ban, сессия не закрыта) и возвращает
+// его роль и срок mute; при отказе — HTTP-статус и ошибка для ответа.
+func joinSession(r *http.Request, svc *service.DataService, sessionID, userID uuid.UUID) (string, time.Time, int, error) {
+	role, err := svc.MemberRole(r.Context(), sessionID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			return "", time.Time{}, http.StatusForbidden, err
+		}
+		log.Printf("join %s: membership: %v", sessionID, err)
+		return "", time.Time{}, http.StatusInternalServerError, errors.New("internal error")
+	}
+	mutedUntil, err := svc.JoinRestrictions(r.Context(), sessionID, userID)
+	if errors.Is(err, service.ErrBanned) {
+		return "", time.Time{}, http.StatusForbidden, err
+	}
+	if errors.Is(err, service.ErrSessionClosed) {
+		return "", time.Time{}, http.StatusConflict, err
+	}
+	if err != nil {
+		log.Printf("join %s: restrictions: %v", sessionID, err)
+		return "", time.Time{}, http.StatusInternalServerError, errors.New("internal error")
+	}
+	return role, mutedUntil, http.StatusOK, nil
 }
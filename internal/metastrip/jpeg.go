@@ -0,0 +1,164 @@
+package metastrip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP2  = 0xE2
+	markerAPP14 = 0xEE
+	markerCOM   = 0xFE
+
+	tagOrientation = 0x0112
+)
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	iccHeader  = []byte("ICC_PROFILE\x00")
+)
+
+// stripJPEG удаляет APP1 (EXIF, XMP), APP13 (IPTC), комментарии и прочие APPn, кроме
+// JFIF (APP0), ICC-профиля (APP2) и Adobe (APP14), влияющих на цвет. Ориентация из EXIF
+// сохраняется минимальным APP1, иначе снимок с телефона отобразится повёрнутым.
+// Данные после SOS копируются без изменений.
+func stripJPEG(src io.Reader, dst io.Writer) (int, error) {
+	r := bufio.NewReader(src)
+	w := bufio.NewWriter(dst)
+	var soi [2]byte
+	if err := readFull(r, soi[:]); err != nil {
+		return 0, err
+	}
+	if soi[0] != 0xFF || soi[1] != markerSOI {
+		return 0, ErrFormat
+	}
+	w.Write(soi[:])
+	removed := 0
+	orientationWritten := false
+	for {
+		marker, err := nextMarker(r)
+		if err != nil {
+			return 0, err
+		}
+		if marker == markerEOI {
+			w.Write([]byte{0xFF, marker})
+			return removed, w.Flush()
+		}
+		var lenBuf [2]byte
+		if err := readFull(r, lenBuf[:]); err != nil {
+			return 0, err
+		}
+		n := int(binary.BigEndian.Uint16(lenBuf[:]))
+		if n < 2 {
+			return 0, ErrFormat
+		}
+		data := make([]byte, n-2)
+		if err := readFull(r, data); err != nil {
+			return 0, err
+		}
+		if keepJPEGSegment(marker, data) {
+			w.Write([]byte{0xFF, marker})
+			w.Write(lenBuf[:])
+			w.Write(data)
+		} else {
+			removed++
+			if marker == markerAPP1 && !orientationWritten && bytes.HasPrefix(data, exifHeader) {
+				if o := exifOrientation(data[len(exifHeader):]); o > 1 && o <= 8 {
+					w.Write(orientationSegment(o))
+					orientationWritten = true
+				}
+			}
+		}
+		if marker == markerSOS {
+			// Дальше — энтропийно-кодированные данные и последующие сканы: копируем как есть.
+			if _, err := io.Copy(w, r); err != nil {
+				return 0, err
+			}
+			return removed, w.Flush()
+		}
+	}
+}
+
+// nextMarker читает маркер, пропуская байты-заполнители 0xFF.
+func nextMarker(r *bufio.Reader) (byte, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, ErrFormat
+	}
+	if b != 0xFF {
+		return 0, ErrFormat
+	}
+	for {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, ErrFormat
+		}
+		if b != 0xFF {
+			return b, nil
+		}
+	}
+}
+
+func keepJPEGSegment(marker byte, data []byte) bool {
+	switch {
+	case marker == markerAPP0, marker == markerAPP14:
+		return true
+	case marker == markerAPP2:
+		return bytes.HasPrefix(data, iccHeader)
+	case marker >= markerAPP0 && marker <= 0xEF, marker == markerCOM:
+		return false
+	}
+	return true
+}
+
+// exifOrientation возвращает тег Orientation из IFD0 блока TIFF (0 — тега нет или блок повреждён).
+func exifOrientation(tiff []byte) uint16 {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		e := ifd + 2 + i*12
+		if e+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[e:]) == tagOrientation && order.Uint16(tiff[e+2:]) == 3 {
+			return order.Uint16(tiff[e+8:])
+		}
+	}
+	return 0
+}
+
+// orientationSegment — APP1 с единственным тегом Orientation (big-endian TIFF, один IFD).
+func orientationSegment(o uint16) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // заголовок, IFD0 по смещению 8
+		0x00, 0x01, // одна запись
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(o >> 8), byte(o), 0x00, 0x00, // Orientation, SHORT, 1
+		0x00, 0x00, 0x00, 0x00, // следующего IFD нет
+	}
+	seg := []byte{0xFF, markerAPP1, 0, 0}
+	seg = append(seg, exifHeader...)
+	seg = append(seg, tiff...)
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(seg)-2))
+	return seg
+}
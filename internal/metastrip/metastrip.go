@@ -0,0 +1,55 @@
+// Package metastrip удаляет метаданные (EXIF, XMP, IPTC, текстовые комментарии) из изображений
+// без перекодирования: копируются только сегменты/чанки с данными изображения.
+package metastrip
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"strings"
+)
+
+// ErrFormat — содержимое не соответствует заявленному формату, метаданные удалить нельзя.
+var ErrFormat = errors.New("metastrip: malformed image")
+
+// Supports сообщает, умеет ли Strip обрабатывать MIME-тип.
+func Supports(contentType string) bool {
+	switch mediaType(contentType) {
+	case "image/jpeg", "image/png", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Strip копирует изображение из src в dst без метаданных и возвращает число удалённых блоков
+// (0 — метаданных не было, dst совпадает с src). src должен быть позиционирован на начало.
+func Strip(contentType string, src io.ReadSeeker, dst io.Writer) (int, error) {
+	switch mediaType(contentType) {
+	case "image/jpeg":
+		return stripJPEG(src, dst)
+	case "image/png":
+		return stripPNG(src, dst)
+	case "image/webp":
+		return stripWebP(src, dst)
+	}
+	return 0, errors.New("metastrip: unsupported type " + contentType)
+}
+
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	mt, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// readFull — io.ReadFull, где обрыв данных считается повреждённым файлом.
+func readFull(r io.Reader, buf []byte) error {
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrFormat
+		}
+		return err
+	}
+	return nil
+}
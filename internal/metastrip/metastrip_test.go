@@ -0,0 +1,297 @@
+package metastrip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	return img
+}
+
+// tiffWithOrientation — блок TIFF с IFD0 из тега Orientation (и ещё одного тега перед ним).
+func tiffWithOrientation(order binary.ByteOrder, o uint16) []byte {
+	b := make([]byte, 8+2+2*12+4)
+	if order == binary.LittleEndian {
+		copy(b, "II")
+	} else {
+		copy(b, "MM")
+	}
+	order.PutUint16(b[2:], 42)
+	order.PutUint32(b[4:], 8)
+	order.PutUint16(b[8:], 2)
+	e := b[10:]
+	order.PutUint16(e, 0x010F) // Make, ASCII
+	order.PutUint16(e[2:], 2)
+	e = b[22:]
+	order.PutUint16(e, tagOrientation)
+	order.PutUint16(e[2:], 3)
+	order.PutUint32(e[4:], 1)
+	order.PutUint16(e[8:], o)
+	return b
+}
+
+func jpegSegment(marker byte, data []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(data)+2))
+	return append(seg, data...)
+}
+
+// jpegWith вставляет сегменты сразу после SOI настоящего JPEG.
+func jpegWith(t *testing.T, segs ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	out := append([]byte{}, buf.Bytes()[:2]...)
+	for _, s := range segs {
+		out = append(out, s...)
+	}
+	return append(out, buf.Bytes()[2:]...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	exif := append(append([]byte{}, exifHeader...), tiffWithOrientation(binary.LittleEndian, 6)...)
+	exifUpright := append(append([]byte{}, exifHeader...), tiffWithOrientation(binary.BigEndian, 1)...)
+	xmp := []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta secret-gps/>")
+	icc := append(append([]byte{}, iccHeader...), 1, 2, 3)
+	cases := []struct {
+		name            string
+		segs            [][]byte
+		wantRemoved     int
+		wantOrientation uint16
+		wantKept        []byte
+	}{
+		{name: "clean"},
+		{name: "exif with orientation", segs: [][]byte{jpegSegment(markerAPP1, exif)}, wantRemoved: 1, wantOrientation: 6},
+		{name: "upright exif", segs: [][]byte{jpegSegment(markerAPP1, exifUpright)}, wantRemoved: 1},
+		{name: "xmp and comment", segs: [][]byte{jpegSegment(markerAPP1, xmp), jpegSegment(markerCOM, []byte("secret-gps"))}, wantRemoved: 2},
+		{name: "iptc", segs: [][]byte{jpegSegment(0xED, []byte("Photoshop 3.0\x00secret-gps"))}, wantRemoved: 1},
+		{name: "icc profile kept", segs: [][]byte{jpegSegment(markerAPP2, icc)}, wantKept: icc},
+		{name: "non-icc app2 removed", segs: [][]byte{jpegSegment(markerAPP2, []byte("MPF\x00secret-gps"))}, wantRemoved: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			removed, err := Strip("image/jpeg", bytes.NewReader(jpegWith(t, tc.segs...)), &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tc.wantRemoved {
+				t.Fatalf("removed = %d, want %d", removed, tc.wantRemoved)
+			}
+			if bytes.Contains(out.Bytes(), []byte("secret-gps")) {
+				t.Fatal("metadata left in output")
+			}
+			if tc.wantKept != nil && !bytes.Contains(out.Bytes(), tc.wantKept) {
+				t.Fatal("kept segment missing")
+			}
+			var orientation uint16
+			if i := bytes.Index(out.Bytes(), exifHeader); i >= 0 {
+				orientation = exifOrientation(out.Bytes()[i+len(exifHeader):])
+			}
+			if orientation != tc.wantOrientation {
+				t.Fatalf("orientation = %d, want %d", orientation, tc.wantOrientation)
+			}
+			if _, err := jpeg.Decode(bytes.NewReader(out.Bytes())); err != nil {
+				t.Fatalf("decode stripped JPEG: %v", err)
+			}
+		})
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	le := tiffWithOrientation(binary.LittleEndian, 8)
+	badOffset := append([]byte{}, le...)
+	binary.LittleEndian.PutUint32(badOffset[4:], 4000)
+	manyEntries := append([]byte{}, le...)
+	binary.LittleEndian.PutUint16(manyEntries[8:], 500)
+	wrongType := append([]byte{}, le...)
+	binary.LittleEndian.PutUint16(wrongType[24:], 4) // LONG вместо SHORT
+	cases := []struct {
+		name string
+		tiff []byte
+		want uint16
+	}{
+		{name: "little endian", tiff: le, want: 8},
+		{name: "big endian", tiff: tiffWithOrientation(binary.BigEndian, 3), want: 3},
+		{name: "short", tiff: le[:6]},
+		{name: "unknown byte order", tiff: append([]byte("XX"), le[2:]...)},
+		{name: "ifd out of range", tiff: badOffset},
+		{name: "truncated ifd", tiff: manyEntries[:30]},
+		{name: "count past end after tag", tiff: manyEntries, want: 8},
+		{name: "wrong type", tiff: wrongType},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exifOrientation(tc.tiff); got != tc.want {
+				t.Fatalf("exifOrientation = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	c := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(c, uint32(len(data)))
+	copy(c[4:], typ)
+	c = append(c, data...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+// pngWith вставляет чанки после IHDR настоящего PNG и дописывает trailer после IEND.
+func pngWith(t *testing.T, trailer []byte, chunks ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	ihdrEnd := len(pngSignature) + 8 + 13 + 4
+	out := append([]byte{}, buf.Bytes()[:ihdrEnd]...)
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	out = append(out, buf.Bytes()[ihdrEnd:]...)
+	return append(out, trailer...)
+}
+
+func TestStripPNG(t *testing.T) {
+	cases := []struct {
+		name        string
+		src         []byte
+		wantRemoved int
+	}{
+		{name: "clean", src: pngWith(t, nil)},
+		{name: "text chunks", src: pngWith(t, nil,
+			pngChunk("tEXt", []byte("Comment\x00secret-gps")),
+			pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00secret-gps")),
+			pngChunk("zTXt", []byte("k\x00\x00secret-gps")),
+		), wantRemoved: 3},
+		{name: "exif and time", src: pngWith(t, nil, pngChunk("eXIf", []byte("MMsecret-gps")), pngChunk("tIME", make([]byte, 7))), wantRemoved: 2},
+		{name: "data after IEND", src: pngWith(t, []byte("secret-gps")), wantRemoved: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			removed, err := Strip("image/png", bytes.NewReader(tc.src), &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tc.wantRemoved {
+				t.Fatalf("removed = %d, want %d", removed, tc.wantRemoved)
+			}
+			if bytes.Contains(out.Bytes(), []byte("secret-gps")) {
+				t.Fatal("metadata left in output")
+			}
+			if _, err := png.Decode(bytes.NewReader(out.Bytes())); err != nil {
+				t.Fatalf("decode stripped PNG: %v", err)
+			}
+		})
+	}
+}
+
+func riffChunkBytes(fourCC string, data []byte) []byte {
+	c := make([]byte, 8, 8+len(data)+1)
+	copy(c, fourCC)
+	binary.LittleEndian.PutUint32(c[4:], uint32(len(data)))
+	c = append(c, data...)
+	if len(data)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	out := []byte("RIFF\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = vp8xFlagEXIF | vp8xFlagXMP | 0x10 // 0x10 — альфа-канал, должен остаться
+	bitstream := riffChunkBytes("VP8L", []byte{0x2F, 1, 2, 3, 4})
+	src := webpFile(
+		riffChunkBytes("VP8X", vp8x),
+		riffChunkBytes("EXIF", []byte("MMsecret-gps")),
+		bitstream,
+		riffChunkBytes("XMP ", []byte("<x:xmpmeta secret-gps/>")),
+	)
+	var out bytes.Buffer
+	removed, err := Strip("image/webp", bytes.NewReader(src), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Fatalf("removed = %d, want 2", removed)
+	}
+	want := webpFile(riffChunkBytes("VP8X", append([]byte{0x10}, vp8x[1:]...)), bitstream)
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("stripped WebP = %q, want %q", out.Bytes(), want)
+	}
+}
+
+func TestStripRejectsMalformed(t *testing.T) {
+	pngSrc := pngWith(t, nil)
+	jpg := jpegWith(t)
+	oversizedChunk := webpFile(riffChunkBytes("VP8L", []byte{1, 2}))
+	binary.LittleEndian.PutUint32(oversizedChunk[16:], 100)
+	badSegmentLen := append([]byte{0xFF, markerSOI, 0xFF, markerAPP1, 0, 1}, jpg[2:]...)
+	cases := []struct {
+		name        string
+		contentType string
+		src         []byte
+	}{
+		{name: "jpeg without SOI", contentType: "image/jpeg", src: pngSrc},
+		{name: "jpeg truncated header", contentType: "image/jpeg", src: jpg[:1]},
+		{name: "jpeg truncated segment", contentType: "image/jpeg", src: jpegWith(t, jpegSegment(markerAPP1, make([]byte, 100)))[:50]},
+		{name: "jpeg segment length below 2", contentType: "image/jpeg", src: badSegmentLen},
+		{name: "png wrong signature", contentType: "image/png", src: jpg},
+		{name: "png truncated before IEND", contentType: "image/png", src: pngSrc[:len(pngSrc)-12]},
+		{name: "png truncated meta chunk", contentType: "image/png", src: pngWith(t, nil, pngChunk("tEXt", make([]byte, 64)))[:60]},
+		{name: "webp wrong header", contentType: "image/webp", src: []byte("RIFF\x04\x00\x00\x00WAVE")},
+		{name: "webp without chunks", contentType: "image/webp", src: webpFile()},
+		{name: "webp chunk past RIFF end", contentType: "image/webp", src: oversizedChunk},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Strip(tc.contentType, bytes.NewReader(tc.src), &bytes.Buffer{})
+			if !errors.Is(err, ErrFormat) {
+				t.Fatalf("Strip error = %v, want ErrFormat", err)
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	cases := map[string]bool{
+		"image/jpeg":               true,
+		"IMAGE/PNG":                true,
+		"image/webp; charset=x":    true,
+		"image/gif":                false,
+		"image/svg+xml":            false,
+		"application/octet-stream": false,
+	}
+	for ct, want := range cases {
+		if got := Supports(ct); got != want {
+			t.Errorf("Supports(%q) = %v, want %v", ct, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package metastrip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetaChunks — чанки с метаданными: EXIF, текст (в том числе XMP в iTXt) и время изменения.
+var pngMetaChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG копирует чанки PNG, пропуская pngMetaChunks; данные после IEND отбрасываются.
+// CRC считается по отдельному чанку, поэтому остальные чанки переносятся без пересчёта.
+func stripPNG(src io.Reader, dst io.Writer) (int, error) {
+	r := bufio.NewReader(src)
+	w := bufio.NewWriter(dst)
+	sig := make([]byte, len(pngSignature))
+	if err := readFull(r, sig); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(sig, pngSignature) {
+		return 0, ErrFormat
+	}
+	w.Write(sig)
+	removed := 0
+	var hdr [8]byte
+	for {
+		if err := readFull(r, hdr[:]); err != nil {
+			return 0, err
+		}
+		n := int64(binary.BigEndian.Uint32(hdr[:4]))
+		if n > 1<<31-1 {
+			return 0, ErrFormat
+		}
+		typ := string(hdr[4:])
+		if pngMetaChunks[typ] {
+			if _, err := r.Discard(int(n) + 4); err != nil {
+				return 0, ErrFormat
+			}
+			removed++
+			continue
+		}
+		w.Write(hdr[:])
+		if _, err := io.CopyN(w, r, n+4); err != nil {
+			if err == io.EOF {
+				return 0, ErrFormat
+			}
+			return 0, err
+		}
+		if typ == "IEND" {
+			if _, err := r.Peek(1); err == nil {
+				removed++
+			}
+			return removed, w.Flush()
+		}
+	}
+}
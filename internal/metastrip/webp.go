@@ -0,0 +1,113 @@
+package metastrip
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Флаги расширенного формата в чанке VP8X.
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+type riffChunk struct {
+	fourCC string
+	offset int64 // начало данных чанка
+	size   int64 // без выравнивания
+}
+
+// stripWebP удаляет чанки EXIF и XMP и снимает соответствующие флаги VP8X.
+// Размер RIFF пишется в заголовке, поэтому сначала читается оглавление чанков, затем они копируются.
+func stripWebP(src io.ReadSeeker, dst io.Writer) (int, error) {
+	var hdr [12]byte
+	if err := readFull(src, hdr[:]); err != nil {
+		return 0, err
+	}
+	if string(hdr[:4]) != "RIFF" || string(hdr[8:]) != "WEBP" {
+		return 0, ErrFormat
+	}
+	riffEnd := 8 + int64(binary.LittleEndian.Uint32(hdr[4:8]))
+	var chunks []riffChunk
+	pos := int64(12)
+	for pos+8 <= riffEnd {
+		if _, err := src.Seek(pos, io.SeekStart); err != nil {
+			return 0, err
+		}
+		var ch [8]byte
+		if err := readFull(src, ch[:]); err != nil {
+			return 0, err
+		}
+		c := riffChunk{fourCC: string(ch[:4]), offset: pos + 8, size: int64(binary.LittleEndian.Uint32(ch[4:]))}
+		if c.offset+c.size > riffEnd {
+			return 0, ErrFormat
+		}
+		chunks = append(chunks, c)
+		pos = c.offset + c.size + c.size&1
+	}
+	if len(chunks) == 0 {
+		return 0, ErrFormat
+	}
+
+	removed := 0
+	size := int64(4) // "WEBP"
+	for _, c := range chunks {
+		if c.fourCC == "EXIF" || c.fourCC == "XMP " {
+			removed++
+			continue
+		}
+		size += 8 + c.size + c.size&1
+	}
+	if size > 1<<32-1 {
+		return 0, ErrFormat
+	}
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(size))
+	if _, err := dst.Write(hdr[:]); err != nil {
+		return 0, err
+	}
+	for _, c := range chunks {
+		if c.fourCC == "EXIF" || c.fourCC == "XMP " {
+			continue
+		}
+		if err := copyChunk(src, dst, c); err != nil {
+			return 0, err
+		}
+	}
+	return removed, nil
+}
+
+func copyChunk(src io.ReadSeeker, dst io.Writer, c riffChunk) error {
+	var ch [8]byte
+	copy(ch[:4], c.fourCC)
+	binary.LittleEndian.PutUint32(ch[4:], uint32(c.size))
+	if _, err := dst.Write(ch[:]); err != nil {
+		return err
+	}
+	if _, err := src.Seek(c.offset, io.SeekStart); err != nil {
+		return err
+	}
+	n := c.size
+	if c.fourCC == "VP8X" && n >= 1 {
+		var flags [1]byte
+		if err := readFull(src, flags[:]); err != nil {
+			return err
+		}
+		flags[0] &^= vp8xFlagEXIF | vp8xFlagXMP
+		if _, err := dst.Write(flags[:]); err != nil {
+			return err
+		}
+		n--
+	}
+	if _, err := io.CopyN(dst, src, n); err != nil {
+		if err == io.EOF {
+			return ErrFormat
+		}
+		return err
+	}
+	if c.size&1 == 1 {
+		// Байт выравнивания пишется заново: в последнем чанке его иногда нет.
+		_, err := dst.Write([]byte{0})
+		return err
+	}
+	return nil
+}
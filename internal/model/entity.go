@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type ChannelMessage struct {
@@ -13,7 +14,11 @@ type ChannelMessage struct {
 	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
 	Kind      string         `gorm:"type:varchar(32);not null;default:'chat'" json:"kind"`
 	Payload   datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
-	CreatedAt time.Time      `json:"created_at"`
+	// Seq — порядковый номер (BIGSERIAL): возрастает в порядке записи, id событий SSE.
+	Seq int64 `gorm:"autoIncrement;not null" json:"seq"`
+	// IdempotencyKey — ключ PublishMessage; уникален в пределах сессии и отправителя.
+	IdempotencyKey *string   `gorm:"type:varchar(128)" json:"idempotency_key,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 func (ChannelMessage) TableName() string { return "channel_messages" }
@@ -26,7 +31,144 @@ type ChannelFile struct {
 	ContentType string    `gorm:"type:varchar(128)" json:"content_type"`
 	SizeBytes   int64     `json:"size_bytes"`
 	StoragePath string    `gorm:"type:varchar(512)" json:"storage_path"`
-	CreatedAt   time.Time `json:"created_at"`
+	SHA256      string    `gorm:"column:sha256;type:char(64);index" json:"sha256"`
+	// PreviewStatus — none/pending/ready/failed; PreviewSizes — готовые размеры превью через запятую ("128,512").
+	PreviewStatus string `gorm:"type:varchar(16);not null;default:'none'" json:"preview_status"`
+	PreviewSizes  string `gorm:"type:varchar(64)" json:"preview_sizes,omitempty"`
+	// ScanStatus — pending/clean/infected/error; скачивание разрешено только для clean.
+	ScanStatus    string     `gorm:"type:varchar(16);not null;default:'pending'" json:"scan_status"`
+	ScanSignature string     `gorm:"type:varchar(255)" json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
+	// ScanAttempts — число неудачных проверок подряд; ScanRetryAt — не раньше какого момента повторить проверку.
+	ScanAttempts int        `gorm:"not null;default:0" json:"-"`
+	ScanRetryAt  *time.Time `json:"-"`
+	// MetadataStripped — из изображения удалены EXIF/XMP/IPTC; OriginalSHA256 — сохранённый исходник (если политика разрешает).
+	MetadataStripped bool      `gorm:"not null;default:false" json:"metadata_stripped"`
+	OriginalSHA256   string    `gorm:"column:original_sha256;type:varchar(64)" json:"-"`
+	CreatedAt        time.Time `json:"created_at"`
+	// DeletedAt — мягкое удаление; содержимое освобождает сборщик мусора после периода ожидания.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (ChannelFile) TableName() string { return "channel_files" }
+
+// FileBlob — содержимое файла, адресуемое SHA-256; одна запись на уникальное содержимое,
+// RefCount — число строк channel_files, ссылающихся на него.
+type FileBlob struct {
+	SHA256      string    `gorm:"column:sha256;type:char(64);primaryKey" json:"sha256"`
+	SizeBytes   int64     `gorm:"not null" json:"size_bytes"`
+	StoragePath string    `gorm:"type:varchar(512);not null" json:"storage_path"`
+	RefCount    int       `gorm:"not null;default:0" json:"ref_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (FileBlob) TableName() string { return "file_blobs" }
+
+// BlobWrite — отметка записи объекта digest в хранилище транзакцией, которая ещё не завершилась.
+type BlobWrite struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SHA256    string    `gorm:"column:sha256;type:char(64);not null;index"`
+	CreatedAt time.Time
+}
+
+func (BlobWrite) TableName() string { return "blob_writes" }
+
+// StorageUsage — учёт занятого места и числа файлов по сессии или пользователю (Scope: "session"/"user").
+type StorageUsage struct {
+	Scope      string    `gorm:"type:varchar(16);primaryKey" json:"scope"`
+	ScopeID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"scope_id"`
+	BytesUsed  int64     `gorm:"not null;default:0" json:"bytes_used"`
+	FilesCount int64     `gorm:"not null;default:0" json:"files_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (StorageUsage) TableName() string { return "storage_usage" }
+
+// BlobKey — ключ данных объекта хранилища, обёрнутый мастер-ключом KeyID (шифрование at rest).
+type BlobKey struct {
+	ObjectKey  string    `gorm:"type:varchar(512);primaryKey" json:"object_key"`
+	Ref        string    `gorm:"type:varchar(32);primaryKey" json:"ref"` // ссылка из заголовка объекта; "" — формат DCE1
+	KeyID      string    `gorm:"type:varchar(64);not null;index" json:"key_id"`
+	WrappedKey []byte    `gorm:"type:bytea;not null" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (BlobKey) TableName() string { return "blob_keys" }
+
+// UploadSession — загрузка напрямую в хранилище по подписанным ссылкам: содержимое попадает
+// в промежуточный объект ObjectKey и становится файлом только после CompleteUpload.
+type UploadSession struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	SessionID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	Filename    string     `gorm:"type:varchar(255);not null" json:"filename"`
+	ContentType string     `gorm:"type:varchar(128)" json:"content_type"`
+	SizeBytes   int64      `gorm:"not null" json:"size_bytes"`
+	SHA256      string     `gorm:"column:sha256;type:char(64);not null" json:"sha256"`
+	ObjectKey   string     `gorm:"type:varchar(512);not null" json:"object_key"`
+	MultipartID string     `gorm:"type:varchar(1024)" json:"-"` // UploadId multipart-загрузки S3
+	Status      string     `gorm:"type:varchar(16);not null;default:'pending'" json:"status"`
+	FileID      *uuid.UUID `gorm:"type:uuid" json:"file_id,omitempty"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (UploadSession) TableName() string { return "upload_sessions" }
+
+// SessionMember — участник сессии и его роль (owner, operator, participant, viewer).
+type SessionMember struct {
+	SessionID uuid.UUID  `gorm:"type:uuid;primaryKey" json:"session_id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role      string     `gorm:"type:varchar(16);not null;default:'participant'" json:"role"`
+	AddedBy   *uuid.UUID `gorm:"type:uuid" json:"added_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (SessionMember) TableName() string { return "session_members" }
+
+// SessionRestriction — действующее ограничение участника: mute (нельзя писать) или ban (нельзя подключиться).
+// ExpiresAt nil — бессрочно.
+type SessionRestriction struct {
+	SessionID uuid.UUID  `gorm:"type:uuid;primaryKey" json:"session_id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Kind      string     `gorm:"type:varchar(16);primaryKey" json:"kind"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	CreatedBy uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (SessionRestriction) TableName() string { return "session_restrictions" }
+
+// ModerationAction — запись журнала модерации (kick, mute, ban).
+type ModerationAction struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	TargetID  uuid.UUID  `gorm:"type:uuid;not null" json:"target_id"`
+	ActorID   uuid.UUID  `gorm:"type:uuid;not null" json:"actor_id"`
+	Action    string     `gorm:"type:varchar(16);not null" json:"action"`
+	Reason    string     `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (ModerationAction) TableName() string { return "moderation_actions" }
+
+// Session — сессия с явным жизненным циклом: open, затем closed (история остаётся доступной).
+type Session struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Title       string         `gorm:"type:varchar(255);not null;default:''" json:"title"`
+	Metadata    datatypes.JSON `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"`
+	Status      string         `gorm:"type:varchar(16);not null;default:'open'" json:"status"`
+	CreatedBy   *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	ClosedBy    *uuid.UUID     `gorm:"type:uuid" json:"closed_by,omitempty"`
+	CloseReason string         `gorm:"type:varchar(500)" json:"close_reason,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	ClosedAt    *time.Time     `json:"closed_at,omitempty"`
+}
+
+func (Session) TableName() string { return "sessions" }
@@ -0,0 +1,101 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd принимает одно соединение, читает команду zINSTREAM и куски до нулевой длины,
+// после чего отвечает reply. Полученное содержимое отправляется в канал.
+func fakeClamd(t *testing.T, reply string) (string, <-chan []byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	got := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(time.Second))
+		cmd := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, cmd); err != nil || string(cmd) != "zINSTREAM\x00" {
+			_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			return
+		}
+		var data bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, conn, int64(size)); err != nil {
+				return
+			}
+		}
+		got <- data.Bytes()
+		_, _ = conn.Write([]byte(reply + "\x00"))
+	}()
+	return "tcp://" + ln.Addr().String(), got
+}
+
+func TestClamdScan(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), clamdChunkSize/8) // два куска INSTREAM
+	cases := []struct {
+		name    string
+		reply   string
+		want    Result
+		wantErr string
+	}{
+		{name: "clean", reply: "stream: OK", want: Result{}},
+		{name: "found", reply: "stream: Eicar-Test-Signature FOUND", want: Result{Infected: true, Signature: "Eicar-Test-Signature"}},
+		{name: "error", reply: "INSTREAM size limit exceeded. ERROR", wantErr: "size limit exceeded"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, got := fakeClamd(t, tc.reply)
+			c, err := NewClamd(addr, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := c.Scan(context.Background(), bytes.NewReader(content))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Scan error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if res != tc.want {
+				t.Fatalf("Scan = %+v, want %+v", res, tc.want)
+			}
+			select {
+			case data := <-got:
+				if !bytes.Equal(data, content) {
+					t.Fatalf("clamd received %d bytes, want %d", len(data), len(content))
+				}
+			case <-time.After(time.Second):
+				t.Fatal("clamd received no stream")
+			}
+		})
+	}
+}
+
+func TestNewClamdRejectsUnknownScheme(t *testing.T) {
+	if _, err := NewClamd("http://localhost:3310", time.Second); err == nil {
+		t.Fatal("NewClamd accepted http scheme")
+	}
+}
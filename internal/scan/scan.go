@@ -0,0 +1,17 @@
+package scan
+
+import (
+	"context"
+	"io"
+)
+
+// Result — вердикт антивирусной проверки.
+type Result struct {
+	Infected  bool
+	Signature string // имя сигнатуры, если Infected
+}
+
+// Scanner проверяет содержимое файла на вредоносный код.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+)
+
+// ManifestName — имя файла с описанием содержимого в корне архива сессии.
+const ManifestName = "manifest.json"
+
+// ArchiveManifest — manifest.json архива: метаданные channel_files и контрольные суммы.
+type ArchiveManifest struct {
+	SessionID   uuid.UUID      `json:"session_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Files       []ArchiveEntry `json:"files"`
+}
+
+// ArchiveEntry — файл сессии в манифесте. Path пуст, если содержимое не вошло в архив (Skipped — причина).
+type ArchiveEntry struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Filename    string    `json:"filename"`
+	Path        string    `json:"path,omitempty"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256"`
+	ScanStatus  string    `json:"scan_status"`
+	CreatedAt   time.Time `json:"created_at"`
+	Skipped     string    `json:"skipped,omitempty"`
+	// ActualSHA256 заполняется, если содержимое в хранилище не совпало с сохранённой суммой.
+	ActualSHA256 string `json:"actual_sha256,omitempty"`
+}
+
+// WriteArchive потоково пишет в w ZIP со всеми файлами сессии (files/<имя>) и manifest.json.
+// Содержимое читается из хранилища по одному файлу и не копируется на диск; файлы, не прошедшие
+// антивирусную проверку, в архив не попадают, но перечислены в манифесте. Контрольная сумма
+// пересчитывается при чтении, расхождение с сохранённой отмечается в манифесте.
+func (s *DataService) WriteArchive(ctx context.Context, w io.Writer, sessionID uuid.UUID) (*ArchiveManifest, error) {
+	var files []model.ChannelFile
+	if err := s.db.WithContext(ctx).Where("session_id = ?", sessionID).
+		Order("created_at ASC, id ASC").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	manifest := &ArchiveManifest{SessionID: sessionID, GeneratedAt: time.Now().UTC(), Files: make([]ArchiveEntry, 0, len(files))}
+	zw := zip.NewWriter(w)
+	names := make(map[string]bool, len(files))
+	for i := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f := &files[i]
+		entry := ArchiveEntry{
+			ID:          f.ID,
+			UserID:      f.UserID,
+			Filename:    f.Filename,
+			ContentType: f.ContentType,
+			SizeBytes:   f.SizeBytes,
+			SHA256:      f.SHA256,
+			ScanStatus:  f.ScanStatus,
+			CreatedAt:   f.CreatedAt,
+		}
+		if err := checkScan(f); err != nil {
+			entry.Skipped = err.Error()
+			manifest.Files = append(manifest.Files, entry)
+			continue
+		}
+		name := archiveName(names, f.Filename)
+		digest, err := s.archiveFile(ctx, zw, f, name)
+		switch {
+		case errors.Is(err, ErrFileNotFound):
+			entry.Skipped = err.Error()
+		case err != nil:
+			return nil, fmt.Errorf("archive %s: %w", f.ID, err)
+		default:
+			entry.Path = name
+			if f.SHA256 != "" && digest != f.SHA256 {
+				entry.ActualSHA256 = digest
+			}
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: manifest.GeneratedAt})
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, zw.Close()
+}
+
+// archiveFile копирует содержимое f в запись name и возвращает фактический SHA-256.
+func (s *DataService) archiveFile(ctx context.Context, zw *zip.Writer, f *model.ChannelFile, name string) (string, error) {
+	if f.StoragePath == "" {
+		return "", ErrFileNotFound
+	}
+	rc, err := s.store.Open(ctx, f.StoragePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", ErrFileNotFound
+		}
+		return "", err
+	}
+	defer rc.Close()
+	method := zip.Deflate
+	if precompressed(f.ContentType) {
+		method = zip.Store
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: f.CreatedAt})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(fw, h), rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// archiveName возвращает уникальное имя записи: одноимённые файлы получают суффикс " (2)", " (3)"...
+func archiveName(used map[string]bool, filename string) string {
+	base := SanitizeFilename(filename)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := "files/" + base
+	for n := 2; used[name]; n++ {
+		name = "files/" + stem + " (" + strconv.Itoa(n) + ")" + ext
+	}
+	used[name] = true
+	return name
+}
+
+// precompressed — форматы, которые не сжимаются повторно; хранятся в архиве без deflate.
+func precompressed(contentType string) bool {
+	mt := mediaType(contentType)
+	switch {
+	case strings.HasPrefix(mt, "image/") && mt != "image/svg+xml" && mt != "image/bmp":
+		return true
+	case strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "audio/"):
+		return true
+	}
+	switch mt {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/x-7z-compressed",
+		"application/x-rar-compressed", "application/pdf":
+		return true
+	}
+	return strings.HasPrefix(mt, "application/vnd.openxmlformats-officedocument.")
+}
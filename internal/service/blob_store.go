@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blobKey — ключ объекта в хранилище по SHA-256: blobs/ab/cd/abcd....
+func blobKey(digest string) string {
+	return "blobs/" + digest[:2] + "/" + digest[2:4] + "/" + digest
+}
+
+// spool копирует содержимое во временный файл, считая SHA-256 и размер.
+// Больше limit байт не читается: превышение возвращает ErrFileTooLarge.
+func spool(r io.Reader, limit int64) (*os.File, string, int64, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, "", 0, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, limit+1))
+	if err == nil && n > limit {
+		err = fmt.Errorf("%w: limit %d", ErrFileTooLarge, limit)
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		discardSpool(tmp)
+		return nil, "", 0, err
+	}
+	return tmp, hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func discardSpool(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// blobWriteGrace — через сколько отметка записи объекта считается оставшейся от отката транзакции.
+const blobWriteGrace = time.Hour
+
+// blobWrites — отметки объектов, записанных в хранилище внутри ещё не завершённой транзакции.
+type blobWrites []uuid.UUID
+
+// acquireBlob добавляет ссылку на содержимое digest (создаёт запись file_blobs при первой ссылке)
+// и записывает объект в хранилище через write, только если его там ещё нет. Вызывается внутри транзакции:
+// строка file_blobs блокируется до commit, что сериализует её с releaseBlob. Перед записью отдельным commit
+// ставится отметка в blob_writes (добавляется в written): если транзакция откатится, строки file_blobs
+// не останется, и объект без ссылок найдёт по отметке reconcileBlobWrites.
+func (s *DataService) acquireBlob(ctx context.Context, tx *gorm.DB, written *blobWrites, digest string, size int64, write func(key string) error) (*model.FileBlob, error) {
+	blob := &model.FileBlob{SHA256: digest, SizeBytes: size, StoragePath: blobKey(digest), RefCount: 1}
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "sha256"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"ref_count":  gorm.Expr("file_blobs.ref_count + 1"),
+			"updated_at": time.Now(),
+		}),
+	}, clause.Returning{}).Create(blob).Error
+	if err != nil {
+		return nil, fmt.Errorf("acquire blob: %w", err)
+	}
+	if blob.RefCount > 1 {
+		exists, err := s.store.Exists(ctx, blob.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("acquire blob: %w", err)
+		}
+		if exists {
+			return blob, nil
+		}
+	}
+	mark := &model.BlobWrite{ID: uuid.New(), SHA256: digest}
+	if err := s.db.WithContext(ctx).Create(mark).Error; err != nil {
+		return nil, fmt.Errorf("acquire blob: %w", err)
+	}
+	*written = append(*written, mark.ID)
+	if err := write(blob.StoragePath); err != nil {
+		return nil, fmt.Errorf("store blob: %w", err)
+	}
+	return blob, nil
+}
+
+// settleBlobWrites вызывается после транзакции с acquireBlob: после commit на объекты ссылается
+// file_blobs, и отметки снимаются; после отката они остаются для reconcileBlobWrites.
+func (s *DataService) settleBlobWrites(ctx context.Context, written blobWrites, committed bool) {
+	if !committed || len(written) == 0 {
+		return
+	}
+	if err := s.db.WithContext(ctx).Delete(&model.BlobWrite{}, "id IN ?", []uuid.UUID(written)).Error; err != nil {
+		log.Printf("blob writes: %v", err)
+	}
+}
+
+// reconcileBlobWrites разбирает отметки старше blobWriteGrace: объект, на который так и не сослалась
+// ни одна строка file_blobs, удаляется из хранилища. Объект не трогается, пока у того же содержимого
+// есть более свежая отметка: его, возможно, сейчас записывает другая транзакция.
+func (s *DataService) reconcileBlobWrites(ctx context.Context) error {
+	var marks []model.BlobWrite
+	cutoff := time.Now().Add(-blobWriteGrace)
+	if err := s.db.WithContext(ctx).Where("created_at < ?", cutoff).Order("created_at").
+		Limit(gcBatchSize).Find(&marks).Error; err != nil {
+		return err
+	}
+	for _, m := range marks {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var blobs []model.FileBlob
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("sha256 = ?", m.SHA256).Find(&blobs).Error; err != nil {
+				return err
+			}
+			var recent int64
+			if err := tx.Model(&model.BlobWrite{}).Where("sha256 = ? AND created_at >= ?", m.SHA256, cutoff).
+				Count(&recent).Error; err != nil {
+				return err
+			}
+			if len(blobs) == 0 && recent == 0 {
+				if err := s.store.Delete(ctx, blobKey(m.SHA256)); err != nil {
+					return fmt.Errorf("delete blob: %w", err)
+				}
+			}
+			return tx.Delete(&model.BlobWrite{}, "id = ?", m.ID).Error
+		})
+		if err != nil {
+			log.Printf("file gc: blob write %s: %v", m.SHA256, err)
+		}
+	}
+	return nil
+}
+
+// putSpool — запись объекта для acquireBlob из временного файла.
+func (s *DataService) putSpool(ctx context.Context, content io.ReadSeeker) func(key string) error {
+	return func(key string) error {
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return s.store.Put(ctx, key, content)
+	}
+}
+
+// releaseBlob снимает ссылку на содержимое digest внутри транзакции tx. Строка с нулём ссылок остаётся:
+// объект удаляет purgeBlob после commit (true — ссылка была последней).
+func (s *DataService) releaseBlob(tx *gorm.DB, digest string) (bool, error) {
+	var blob model.FileBlob
+	res := tx.Model(&blob).Clauses(clause.Returning{}).
+		Where("sha256 = ? AND ref_count > 0", digest).
+		Updates(map[string]interface{}{"ref_count": gorm.Expr("ref_count - 1"), "updated_at": time.Now()})
+	if res.Error != nil {
+		return false, fmt.Errorf("release blob: %w", res.Error)
+	}
+	return res.RowsAffected > 0 && blob.RefCount == 0, nil
+}
+
+// purgeBlob удаляет объект и превью содержимого digest, если на него не осталось ссылок, затем строку
+// file_blobs. Строка блокируется на время удаления: acquireBlob того же содержимого ждёт и записывает
+// объект заново. При ошибке строка остаётся, и удаление повторяет следующий проход FileGC.
+func (s *DataService) purgeBlob(ctx context.Context, digest string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var blob model.FileBlob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sha256 = ? AND ref_count <= 0", digest).First(&blob).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.store.Delete(ctx, blob.StoragePath); err != nil {
+			return fmt.Errorf("delete blob: %w", err)
+		}
+		if s.previews != nil {
+			if err := s.previews.deleteFor(ctx, digest); err != nil {
+				return fmt.Errorf("delete previews: %w", err)
+			}
+		}
+		return tx.Delete(&model.FileBlob{}, "sha256 = ? AND ref_count <= 0", digest).Error
+	})
+}
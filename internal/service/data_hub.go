@@ -1,35 +1,130 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrReadOnly — зритель (viewer) не может писать в сессию.
+	ErrReadOnly = fmt.Errorf("%w: viewers cannot send messages", ErrForbidden)
+	// ErrRateLimited — кадр отклонён лимитом входящих кадров.
+	ErrRateLimited = errors.New("rate limit exceeded")
 )
 
 type DataHub struct {
 	mu       sync.RWMutex
 	sessions map[uuid.UUID]map[uuid.UUID]*DataConn
+	subs     map[*Subscription]struct{}
+	limiter  *RateLimiter // nil — входящие кадры не ограничиваются
+	// closed — сессии, закрытые на этом экземпляре (closeSession), и время закрытия: подключение,
+	// прошедшее проверку до закрытия, не регистрируется, и его кадры не сохраняются. Отметки хранятся
+	// closedSessionTTL; дальше закрытую сессию отклоняют проверки в БД (JoinRestrictions, PersistFrame).
+	closed map[uuid.UUID]time.Time
+}
+
+// Frame — исходящий кадр: Seq — номер сообщения в channel_messages (0 — не сохраняется: presence, error),
+// Kind — вид (MessageKindData для кадров клиентов, иначе тип события).
+type Frame struct {
+	Seq  int64
+	Kind string
+	Data []byte
+
+	close *CloseInfo // закрыть Transport после кадров, поставленных в очередь раньше (closeAfterQueued)
+}
+
+// CloseInfo — код и причина закрытия подключения сервером (kick, ban, лимиты).
+type CloseInfo struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason,omitempty"`
 }
 
 type DataConn struct {
 	SessionID uuid.UUID
 	UserID    uuid.UUID
-	conn      *websocket.Conn
-	send      chan []byte
-	sendOnce  sync.Once
+	Role      string       // роль на момент подключения; viewer только получает сообщения
+	muted     atomic.Int64 // UnixNano окончания mute; 0 — не ограничен
+	transport Transport    // nil — кадры читаются из Frames (SSE, long-polling, gRPC)
+	send      chan Frame
+	sendMu    sync.RWMutex // enqueue и closeSend: кадр не отправляется в закрытую очередь
+	sendDone  bool
+	limits    *connLimits
+
+	// Подключения без Transport закрываются через done; closed — код и причина.
+	done      chan struct{}
+	closeOnce sync.Once
+	closed    CloseInfo
 }
 
 func NewDataHub() *DataHub {
 	return &DataHub{
 		sessions: make(map[uuid.UUID]map[uuid.UUID]*DataConn),
+		closed:   make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (c *DataConn) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.sendDone {
+		c.sendDone = true
+		close(c.send)
 	}
 }
 
-func (c *DataConn) closeSend() { c.sendOnce.Do(func() { close(c.send) }) }
+// Frames — очередь кадров клиента; закрывается, когда подключение снято с регистрации или заменено новым.
+func (c *DataConn) Frames() <-chan Frame { return c.send }
+
+// Done закрывается, когда сервер разрывает подключение без Transport (kick, ban); причина — CloseInfo.
+func (c *DataConn) Done() <-chan struct{} { return c.done }
+
+// CloseInfo — код и причина закрытия после Done.
+func (c *DataConn) CloseInfo() CloseInfo { return c.closed }
+
+// Pending забирает кадры, уже стоящие в очереди (чтобы доставить их перед закрытием после Done).
+func (c *DataConn) Pending() []Frame {
+	var out []Frame
+	for {
+		select {
+		case f, ok := <-c.send:
+			if !ok {
+				return out
+			}
+			out = append(out, f)
+		default:
+			return out
+		}
+	}
+}
 
-func (h *DataHub) Register(sessionID, userID uuid.UUID, conn *websocket.Conn) *DataConn {
+// Register подключает пользователя к сессии с ролью role (прежнее подключение того же пользователя
+// закрывается). С Transport кадры доставляют WritePump и ReadPump; t == nil — подключение без него
+// (SSE, long-polling, gRPC): кадры читаются из Frames, закрытие сервером — Done.
+// Новому клиенту отправляется presence.state со всеми подключёнными, остальным — presence.joined.
+// В закрытую сессию подключение не добавляется и сразу закрывается с кодом CloseSessionClosed.
+func (h *DataHub) Register(sessionID, userID uuid.UUID, role string, t Transport) *DataConn {
 	h.mu.Lock()
+	if at, ok := h.closed[sessionID]; ok && time.Since(at) <= closedSessionTTL {
+		h.mu.Unlock()
+		c := &DataConn{
+			SessionID: sessionID,
+			UserID:    userID,
+			Role:      role,
+			transport: t,
+			send:      make(chan Frame, 1),
+			limits:    &connLimits{},
+			done:      make(chan struct{}),
+		}
+		c.closeAfterQueued(CloseSessionClosed, "session closed")
+		return c
+	}
 	if h.sessions[sessionID] == nil {
 		h.sessions[sessionID] = make(map[uuid.UUID]*DataConn)
 	}
@@ -37,70 +132,244 @@ func (h *DataHub) Register(sessionID, userID uuid.UUID, conn *websocket.Conn) *D
 		old.closeSend()
 		delete(h.sessions[sessionID], userID)
 	}
-	c := &DataConn{SessionID: sessionID, UserID: userID, conn: conn, send: make(chan []byte, 256)}
+	c := &DataConn{
+		SessionID: sessionID,
+		UserID:    userID,
+		Role:      role,
+		transport: t,
+		send:      make(chan Frame, 256),
+		limits:    &connLimits{},
+		done:      make(chan struct{}),
+	}
 	h.sessions[sessionID][userID] = c
+	state := PresenceState{Members: make([]PresenceData, 0, len(h.sessions[sessionID]))}
+	for _, other := range h.sessions[sessionID] {
+		state.Members = append(state.Members, PresenceData{UserID: other.UserID, Role: other.Role})
+	}
 	h.mu.Unlock()
+	c.sendEvent(Event{Type: EventPresenceState, SessionID: sessionID, Data: state})
+	h.publishExcept(Event{Type: EventPresenceJoined, SessionID: sessionID, Data: PresenceData{UserID: userID, Role: role}}, &userID)
 	return c
 }
 
-func (h *DataHub) Unregister(sessionID, userID uuid.UUID) {
+// Unregister отключает c; если пользователь уже переподключился, новое подключение не затрагивается.
+func (h *DataHub) Unregister(c *DataConn) {
 	h.mu.Lock()
-	if m := h.sessions[sessionID]; m != nil {
-		if c, ok := m[userID]; ok {
-			c.closeSend()
-			delete(m, userID)
+	left := false
+	if m := h.sessions[c.SessionID]; m != nil {
+		if cur, ok := m[c.UserID]; ok && cur == c {
+			delete(m, c.UserID)
+			left = true
 		}
 		if len(m) == 0 {
-			delete(h.sessions, sessionID)
+			delete(h.sessions, c.SessionID)
 		}
 	}
+	c.closeSend()
 	h.mu.Unlock()
+	if left {
+		h.Publish(Event{Type: EventPresenceLeft, SessionID: c.SessionID, Data: PresenceData{UserID: c.UserID, Role: c.Role}})
+	}
 }
 
-func (h *DataHub) Broadcast(sessionID uuid.UUID, msg []byte, excludeUserID *uuid.UUID) {
+// Sender возвращает отправителя кадров от имени пользователя вне потока подключения (POST к SSE):
+// его подключение на этом экземпляре, а если его нет — незарегистрированный DataConn с ролью role
+// и сроком mute mutedUntil. Лимиты такого отправителя общие для всех его запросов (RateLimiter).
+func (h *DataHub) Sender(sessionID, userID uuid.UUID, role string, mutedUntil time.Time) *DataConn {
 	h.mu.RLock()
-	m, ok := h.sessions[sessionID]
-	if !ok {
-		h.mu.RUnlock()
-		return
+	c := h.sessions[sessionID][userID]
+	h.mu.RUnlock()
+	if c != nil {
+		return c
+	}
+	c = &DataConn{SessionID: sessionID, UserID: userID, Role: role}
+	c.MuteUntil(mutedUntil)
+	if h.limiter != nil {
+		c.limits = h.limiter.detached(sessionID, userID)
+	} else {
+		c.limits = &connLimits{}
 	}
+	return c
+}
+
+func (h *DataHub) Broadcast(sessionID uuid.UUID, msg []byte, excludeUserID *uuid.UUID) {
+	h.broadcast(sessionID, Frame{Kind: MessageKindData, Data: msg}, excludeUserID)
+}
+
+// broadcast рассылает кадр подключённым к сессии (кроме excludeUserID) и подпискам (Subscribe).
+func (h *DataHub) broadcast(sessionID uuid.UUID, f Frame, excludeUserID *uuid.UUID) {
+	h.mu.RLock()
+	m := h.sessions[sessionID]
 	copy := make(map[uuid.UUID]*DataConn, len(m))
 	for k, v := range m {
 		copy[k] = v
 	}
+	subs := h.subscribersLocked(sessionID, f.Kind)
 	h.mu.RUnlock()
+	for _, s := range subs {
+		s.deliver(SessionFrame{SessionID: sessionID, Frame: f})
+	}
 	for uid, c := range copy {
 		if excludeUserID != nil && uid == *excludeUserID {
 			continue
 		}
 		if c != nil {
-			select {
-			case c.send <- msg:
-			default:
-			}
+			c.enqueue(f)
 		}
 	}
 }
 
+// enqueue ставит кадр в очередь клиента; при переполненной очереди или отключённом клиенте кадр теряется (false).
+func (c *DataConn) enqueue(f Frame) bool {
+	c.sendMu.RLock()
+	defer c.sendMu.RUnlock()
+	if c.sendDone || c.send == nil {
+		return false
+	}
+	select {
+	case c.send <- f:
+		return true
+	default:
+		return false
+	}
+}
+
+// WritePump отправляет кадры из очереди через Transport, пока подключение зарегистрировано.
 func (c *DataConn) WritePump() {
-	defer c.conn.Close()
-	for msg := range c.send {
-		if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+	defer c.transport.Close(0, "")
+	for f := range c.send {
+		if f.close != nil {
+			_ = c.transport.Close(f.close.Code, f.close.Reason)
+			return
+		}
+		if err := c.transport.Send(f); err != nil {
 			return
 		}
 	}
 }
 
-func (c *DataConn) ReadPump(hub *DataHub, persist func(sessionID, userID uuid.UUID, payload []byte)) {
-	defer c.conn.Close()
+// PersistFunc сохраняет кадр в историю и возвращает его номер (Seq).
+type PersistFunc func(sessionID, userID uuid.UUID, payload []byte) (int64, error)
+
+// Relay принимает кадр клиента c: проверяет лимиты (SetRateLimiter), роль и mute, сохраняет кадр
+// через persist и рассылает его остальным участникам сессии с номером сохранённого сообщения.
+// Ошибки: ErrSessionClosed (в том числе от persist), ErrRateLimited, ErrReadOnly, ErrMuted;
+// при другой ошибке записи кадр всё равно рассылается.
+func (h *DataHub) Relay(c *DataConn, payload []byte, persist PersistFunc) (int64, error) {
+	if h.isClosed(c.SessionID) {
+		return 0, ErrSessionClosed
+	}
+	if err := h.admit(c, payload); err != nil {
+		return 0, err
+	}
+	if c.Role == RoleViewer {
+		return 0, ErrReadOnly
+	}
+	if until := c.muted.Load(); until != 0 && time.Now().UnixNano() < until {
+		return 0, ErrMuted
+	}
+	var seq int64
+	if persist != nil {
+		var err error
+		seq, err = persist(c.SessionID, c.UserID, payload)
+		switch {
+		case errors.Is(err, ErrSessionClosed):
+			// Закрытие не дошло до этого экземпляра (уведомление пропущено или экземпляр перезапущен).
+			h.markClosed(c.SessionID)
+			return 0, ErrSessionClosed
+		case err != nil:
+			log.Printf("session %s: persist frame: %v", c.SessionID, err)
+		}
+	}
+	h.broadcast(c.SessionID, Frame{Seq: seq, Kind: MessageKindData, Data: payload}, &c.UserID)
+	return seq, nil
+}
+
+// ReadPump читает кадры клиента и передаёт их в HandleFrame, пока подключение открыто.
+func (c *DataConn) ReadPump(hub *DataHub, persist PersistFunc) {
+	defer c.transport.Close(0, "")
 	for {
-		_, message, err := c.conn.ReadMessage()
+		message, err := c.transport.Receive()
 		if err != nil {
 			break
 		}
-		hub.Broadcast(c.SessionID, message, &c.UserID)
-		if persist != nil {
-			persist(c.SessionID, c.UserID, message)
+		if !hub.HandleFrame(c, message, persist) {
+			return
+		}
+	}
+}
+
+// HandleFrame ретранслирует кадр клиента в сессию (Relay). Кадры сверх лимитов, кадры зрителей (viewer)
+// и участников под mute не рассылаются и не сохраняются: в ответ клиент получает событие error
+// (rate_limited — не чаще раза в секунду, read_only, muted). false — подключение закрыто за нарушения лимитов
+// или потому, что сессия закрыта.
+func (h *DataHub) HandleFrame(c *DataConn, payload []byte, persist PersistFunc) bool {
+	_, err := h.Relay(c, payload, persist)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrSessionClosed):
+		c.closeWith(CloseSessionClosed, "session closed")
+		return false
+	case errors.Is(err, ErrRateLimited):
+		if c.limits.closed.Load() {
+			return false
+		}
+		if c.limits.errorDue(time.Now()) {
+			c.sendError(ErrorCodeRateLimited, err.Error())
 		}
+	case errors.Is(err, ErrReadOnly):
+		c.sendError(ErrorCodeReadOnly, "viewers cannot send messages")
+	case errors.Is(err, ErrMuted):
+		c.sendError(ErrorCodeMuted, "muted until "+time.Unix(0, c.muted.Load()).UTC().Format(time.RFC3339))
+	}
+	return true
+}
+
+func (c *DataConn) sendError(code, message string) {
+	c.sendEvent(Event{Type: EventError, SessionID: c.SessionID, Data: ErrorData{Code: code, Message: message}})
+}
+
+// sendEvent ставит событие в очередь только этого клиента; при переполненной очереди событие теряется.
+func (c *DataConn) sendEvent(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	msg, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("event %s: %v", ev.Type, err)
+		return
+	}
+	c.enqueue(Frame{Seq: ev.Seq, Data: msg})
+}
+
+// MuteUntil запрещает клиенту писать в сессию до until.
+func (c *DataConn) MuteUntil(until time.Time) {
+	if until.IsZero() {
+		c.muted.Store(0)
+		return
+	}
+	c.muted.Store(until.UnixNano())
+}
+
+// closeAfterQueued — closeWith после доставки кадров, уже стоящих в очереди (подключения без Transport
+// дочитывают их сами: Pending); при переполненной очереди закрывает сразу.
+func (c *DataConn) closeAfterQueued(code int, reason string) {
+	if c.transport == nil || !c.enqueue(Frame{close: &CloseInfo{Code: code, Reason: reason}}) {
+		c.closeWith(code, reason)
+	}
+}
+
+// closeWith закрывает Transport с кодом и причиной; ReadPump завершается, и обработчик снимает
+// регистрацию. Подключение без Transport получает Done.
+func (c *DataConn) closeWith(code int, reason string) {
+	if c.transport != nil {
+		_ = c.transport.Close(code, reason)
+		return
+	}
+	if c.done != nil {
+		c.closeOnce.Do(func() {
+			c.closed = CloseInfo{Code: code, Reason: reason}
+			close(c.done)
+		})
 	}
 }
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testWait = time.Second
+
+// connect регистрирует пользователя через MemTransport и запускает WritePump и ReadPump.
+func connect(t *testing.T, h *DataHub, sessionID, userID uuid.UUID, role string, persist PersistFunc) (*DataConn, *MemTransport) {
+	t.Helper()
+	tr := NewMemTransport(64)
+	c := h.Register(sessionID, userID, role, tr)
+	go c.WritePump()
+	go func() {
+		c.ReadPump(h, persist)
+		h.Unregister(c)
+	}()
+	t.Cleanup(func() { _ = tr.Close(0, "") })
+	return c, tr
+}
+
+// next возвращает следующий кадр, отправленный клиенту.
+func next(t *testing.T, tr *MemTransport) Frame {
+	t.Helper()
+	select {
+	case f := <-tr.Sent():
+		return f
+	case <-time.After(testWait):
+		t.Fatal("no frame sent")
+		return Frame{}
+	}
+}
+
+// nextEvent пропускает кадры до события типа typ.
+func nextEvent(t *testing.T, tr *MemTransport, typ string) Event {
+	t.Helper()
+	for {
+		f := next(t, tr)
+		var ev struct {
+			Event
+			Data json.RawMessage `json:"data"`
+		}
+		if json.Unmarshal(f.Data, &ev) == nil && ev.Type == typ {
+			ev.Event.Data = ev.Data
+			return ev.Event
+		}
+	}
+}
+
+func expectNoFrame(t *testing.T, tr *MemTransport) {
+	t.Helper()
+	select {
+	case f := <-tr.Sent():
+		t.Fatalf("unexpected frame %s", f.Data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func waitClosed(t *testing.T, tr *MemTransport) CloseInfo {
+	t.Helper()
+	select {
+	case <-tr.Done():
+		return tr.CloseInfo()
+	case <-time.After(testWait):
+		t.Fatal("transport not closed")
+		return CloseInfo{}
+	}
+}
+
+func TestRegisterReplacesConnection(t *testing.T) {
+	h := NewDataHub()
+	sessionID, userID := uuid.New(), uuid.New()
+	_, first := connect(t, h, sessionID, userID, RoleParticipant, nil)
+	nextEvent(t, first, EventPresenceState)
+
+	_, second := connect(t, h, sessionID, userID, RoleParticipant, nil)
+	if info := waitClosed(t, first); info.Code != 0 {
+		t.Fatalf("replaced connection closed with %+v, want plain close", info)
+	}
+	ev := nextEvent(t, second, EventPresenceState)
+	var state PresenceState
+	if err := json.Unmarshal(ev.Data.(json.RawMessage), &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Members) != 1 || state.Members[0].UserID != userID {
+		t.Fatalf("presence.state members = %+v, want only the new connection", state.Members)
+	}
+
+	h.Broadcast(sessionID, []byte(`{"n":1}`), nil)
+	if f := next(t, second); string(f.Data) != `{"n":1}` {
+		t.Fatalf("frame = %s", f.Data)
+	}
+}
+
+func TestBroadcastExcludesSender(t *testing.T) {
+	h := NewDataHub()
+	sessionID, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	ca, ta := connect(t, h, sessionID, alice, RoleParticipant, nil)
+	nextEvent(t, ta, EventPresenceState)
+	_, tb := connect(t, h, sessionID, bob, RoleParticipant, nil)
+	nextEvent(t, tb, EventPresenceState)
+	nextEvent(t, ta, EventPresenceJoined)
+
+	var persisted []string
+	persist := func(_, userID uuid.UUID, payload []byte) (int64, error) {
+		persisted = append(persisted, userID.String()+":"+string(payload))
+		return int64(len(persisted)), nil
+	}
+	seq, err := h.Relay(ca, []byte(`{"text":"hi"}`), persist)
+	if err != nil || seq != 1 {
+		t.Fatalf("Relay = %d, %v", seq, err)
+	}
+	f := next(t, tb)
+	if string(f.Data) != `{"text":"hi"}` || f.Seq != 1 || f.Kind != MessageKindData {
+		t.Fatalf("bob got %+v", f)
+	}
+	expectNoFrame(t, ta)
+
+	h.Broadcast(sessionID, []byte(`{"x":1}`), &bob)
+	if f := next(t, ta); string(f.Data) != `{"x":1}` {
+		t.Fatalf("alice got %s", f.Data)
+	}
+	expectNoFrame(t, tb)
+}
+
+func TestRejectedFramesGetErrorEvents(t *testing.T) {
+	h := NewDataHub()
+	sessionID, viewer, muted, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	persist := func(uuid.UUID, uuid.UUID, []byte) (int64, error) {
+		t.Error("rejected frame was persisted")
+		return 0, nil
+	}
+	_, to := connect(t, h, sessionID, other, RoleParticipant, nil)
+	nextEvent(t, to, EventPresenceState)
+
+	_, tv := connect(t, h, sessionID, viewer, RoleViewer, persist)
+	nextEvent(t, tv, EventPresenceState)
+	if err := tv.Deliver([]byte(`{"text":"hi"}`)); err != nil {
+		t.Fatal(err)
+	}
+	ev := nextEvent(t, tv, EventError)
+	var data ErrorData
+	if err := json.Unmarshal(ev.Data.(json.RawMessage), &data); err != nil || data.Code != ErrorCodeReadOnly {
+		t.Fatalf("viewer error = %+v, %v", data, err)
+	}
+
+	cm, tm := connect(t, h, sessionID, muted, RoleParticipant, persist)
+	cm.MuteUntil(time.Now().Add(time.Hour))
+	nextEvent(t, tm, EventPresenceState)
+	if err := tm.Deliver([]byte(`{"text":"hi"}`)); err != nil {
+		t.Fatal(err)
+	}
+	ev = nextEvent(t, tm, EventError)
+	if err := json.Unmarshal(ev.Data.(json.RawMessage), &data); err != nil || data.Code != ErrorCodeMuted {
+		t.Fatalf("muted error = %+v, %v", data, err)
+	}
+
+	// Остальные участники видят только presence, но не отклонённые кадры.
+	for i := 0; i < 2; i++ {
+		nextEvent(t, to, EventPresenceJoined)
+	}
+	expectNoFrame(t, to)
+}
+
+func TestCloseAfterQueuedDeliversPendingFrames(t *testing.T) {
+	h := NewDataHub()
+	sessionID, userID := uuid.New(), uuid.New()
+	tr := NewMemTransport(64)
+	c := h.Register(sessionID, userID, RoleParticipant, tr)
+	// WritePump ещё не запущен: кадры и закрытие ждут в очереди подключения.
+	for _, msg := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		h.Broadcast(sessionID, []byte(msg), nil)
+	}
+	c.closeAfterQueued(CloseKicked, "kicked")
+	h.Broadcast(sessionID, []byte(`{"n":4}`), nil)
+	go c.WritePump()
+
+	info := waitClosed(t, tr)
+	if info.Code != CloseKicked || info.Reason != "kicked" {
+		t.Fatalf("close = %+v", info)
+	}
+	nextEvent(t, tr, EventPresenceState)
+	for _, want := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		if f := next(t, tr); string(f.Data) != want {
+			t.Fatalf("frame = %s, want %s", f.Data, want)
+		}
+	}
+	expectNoFrame(t, tr)
+}
+
+func TestKickDeliversNoticeBeforeClose(t *testing.T) {
+	for _, tc := range []struct {
+		action string
+		code   int
+	}{
+		{ModerationKick, CloseKicked},
+		{ModerationBan, CloseBanned},
+	} {
+		t.Run(tc.action, func(t *testing.T) {
+			h := NewDataHub()
+			sessionID, userID := uuid.New(), uuid.New()
+			_, tr := connect(t, h, sessionID, userID, RoleParticipant, nil)
+			nextEvent(t, tr, EventPresenceState)
+
+			h.ApplyModeration(ModerationNotice{Action: tc.action, SessionID: sessionID, UserID: userID, Reason: "spam"})
+			info := waitClosed(t, tr)
+			if info.Code != tc.code {
+				t.Fatalf("close = %+v, want code %d", info, tc.code)
+			}
+			ev := nextEvent(t, tr, "moderation."+tc.action)
+			var n ModerationNotice
+			if err := json.Unmarshal(ev.Data.(json.RawMessage), &n); err != nil || n.UserID != userID || n.Reason != "spam" {
+				t.Fatalf("notice = %+v, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestPersistReportsClosedSession(t *testing.T) {
+	h := NewDataHub()
+	sessionID, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	// Закрытие прошло на другом экземпляре: об этом знает только запись в БД.
+	persist := func(uuid.UUID, uuid.UUID, []byte) (int64, error) { return 0, ErrSessionClosed }
+	_, ta := connect(t, h, sessionID, alice, RoleParticipant, persist)
+	nextEvent(t, ta, EventPresenceState)
+	_, tb := connect(t, h, sessionID, bob, RoleParticipant, nil)
+	nextEvent(t, tb, EventPresenceState)
+	nextEvent(t, ta, EventPresenceJoined)
+
+	if err := ta.Deliver([]byte(`{"text":"late"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if info := waitClosed(t, ta); info.Code != CloseSessionClosed {
+		t.Fatalf("close = %+v", info)
+	}
+	if f := next(t, tb); f.Kind != EventPresenceLeft {
+		t.Fatalf("bob got %s %s, want only presence.left", f.Kind, f.Data)
+	}
+	expectNoFrame(t, tb)
+
+	_, tc := connect(t, h, sessionID, uuid.New(), RoleParticipant, nil)
+	if info := waitClosed(t, tc); info.Code != CloseSessionClosed {
+		t.Fatalf("register into closed session: close = %+v", info)
+	}
+}
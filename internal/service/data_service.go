@@ -1,41 +1,179 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/metastrip"
 	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+	"github.com/psds-microservice/data-channel-service/internal/urlsign"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-const maxFileSizeBytes = 50 << 20   // 50 MiB
-const maxFilenameLen = 255
 const maxStoragePathLen = 2048
 
+// ErrFileNotFound — файла нет в channel_files (или его содержимое утеряно).
+var ErrFileNotFound = errors.New("file not found")
+
 // DataServicer — интерфейс для gRPC Deps (Dependency Inversion).
 type DataServicer interface {
 	GetHistory(sessionID uuid.UUID, limit int) ([]model.ChannelMessage, error)
-	SaveFile(sessionID, userID uuid.UUID, filename, contentType string, sizeBytes int64, storagePath string) (*model.ChannelFile, error)
+	UploadFile(ctx context.Context, u Upload) (*model.ChannelFile, error)
+	ListFiles(ctx context.Context, sessionID uuid.UUID, filter FileFilter, cursor string, limit int) ([]model.ChannelFile, string, error)
+	GetFile(ctx context.Context, fileID uuid.UUID) (*model.ChannelFile, error)
+	DeleteFile(ctx context.Context, fileID, actorID uuid.UUID) (*model.ChannelFile, error)
+	GetUsage(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) ([]Usage, error)
+	FileURL(fileID uuid.UUID, ttl time.Duration, userID *uuid.UUID) (string, time.Time)
+	IssueFileURL(ctx context.Context, fileID uuid.UUID, ttl time.Duration, userID *uuid.UUID) (string, time.Time, error)
+	CreateUploadSession(ctx context.Context, req DirectUploadRequest) (*DirectUpload, error)
+	CompleteUpload(ctx context.Context, uploadID, userID uuid.UUID, parts []storage.Part) (*model.ChannelFile, error)
+	CheckMember(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) error
+	CheckFileAccess(ctx context.Context, fileID uuid.UUID, callerID *uuid.UUID) error
+	MemberRole(ctx context.Context, sessionID, userID uuid.UUID) (string, error)
+	JoinRestrictions(ctx context.Context, sessionID, userID uuid.UUID) (time.Time, error)
+	PersistFrame(sessionID, userID uuid.UUID, payload []byte) (int64, error)
+	PublishMessage(ctx context.Context, req PublishRequest) (*model.ChannelMessage, bool, error)
+	MessagesMatching(ctx context.Context, f MessageFilter, afterSeq int64, limit int) ([]model.ChannelMessage, error)
+	CreateSession(ctx context.Context, in SessionInput) (*model.Session, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error)
+	CloseSession(ctx context.Context, sessionID, actorID uuid.UUID, reason string) (*model.Session, error)
+	AddMember(ctx context.Context, sessionID, actorID, userID uuid.UUID, role string, trusted bool) (*model.SessionMember, error)
+	RemoveMember(ctx context.Context, sessionID, actorID, userID uuid.UUID, trusted bool) error
+	ListMembers(ctx context.Context, sessionID, actorID uuid.UUID) ([]model.SessionMember, error)
+	DeleteMessage(ctx context.Context, messageID, actorID uuid.UUID) (*model.ChannelMessage, error)
+	KickParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, reason string) (*model.ModerationAction, error)
+	MuteParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, d time.Duration, reason string) (*model.ModerationAction, error)
+	BanParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, d time.Duration, reason string) (*model.ModerationAction, error)
 }
 
 type DataService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	store    storage.Storage
+	policy   FilePolicy
+	previews *Thumbnailer
+	scans    *ScanWorker
+	events   EventPublisher
+	mods     ModeratorResolver
+	members  MembershipResolver
+
+	moderation ModerationBus
+	quotas     Quotas
+	sanitize   SanitizePolicy
+
+	states sessionStates
+
+	signer    *urlsign.Signer
+	urlTTL    time.Duration
+	urlMaxTTL time.Duration
+
+	direct    storage.DirectUploader
+	directTTL time.Duration
+	directMax int64
+	partSize  int64
 }
 
-func NewDataService(db *gorm.DB) *DataService {
-	return &DataService{db: db}
+func NewDataService(db *gorm.DB, store storage.Storage, policy FilePolicy) *DataService {
+	return &DataService{db: db, store: store, policy: policy}
 }
 
-func (s *DataService) AppendMessage(sessionID, userID uuid.UUID, kind string, payload datatypes.JSON) error {
-	return s.db.Create(&model.ChannelMessage{
+// Policy возвращает действующую политику приёма файлов.
+func (s *DataService) Policy() FilePolicy { return s.policy }
+
+// SetThumbnailer включает фоновую генерацию превью для загружаемых изображений.
+func (s *DataService) SetThumbnailer(t *Thumbnailer) { s.previews = t }
+
+// SetScanWorker включает антивирусную проверку: до её завершения файл недоступен для скачивания.
+func (s *DataService) SetScanWorker(w *ScanWorker) { s.scans = w }
+
+// SetEventPublisher задаёт получателя служебных событий сессии (обычно DataHub).
+func (s *DataService) SetEventPublisher(p EventPublisher) { s.events = p }
+
+// SetModeratorResolver задаёт источник модераторов сессии; без него модераторы — владелец и операторы (MemberRole).
+func (s *DataService) SetModeratorResolver(r ModeratorResolver) { s.mods = r }
+
+// AppendMessage сохраняет сообщение в историю сессии; Seq заполняется базой.
+func (s *DataService) AppendMessage(sessionID, userID uuid.UUID, kind string, payload datatypes.JSON) (*model.ChannelMessage, error) {
+	msg := &model.ChannelMessage{
 		SessionID: sessionID,
 		UserID:    userID,
 		Kind:      kind,
 		Payload:   payload,
-	}).Error
+	}
+	if _, err := s.insertMessage(context.Background(), msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// insertMessage вставляет сообщение под транзакционной advisory-блокировкой сессии.
+// Seq (BIGSERIAL) выдаётся до коммита: без блокировки параллельная вставка с меньшим
+// номером может стать видимой позже большего, и курсор (Last-Event-ID, after_seq) её
+// пропустит. Под блокировкой номера одной сессии фиксируются строго по возрастанию.
+func (s *DataService) insertMessage(ctx context.Context, msg *model.ChannelMessage, clauses ...clause.Expression) (int64, error) {
+	var rows int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))",
+			"channel_messages:"+msg.SessionID.String()).Error; err != nil {
+			return err
+		}
+		res := tx.Clauses(clauses...).Create(msg)
+		rows = res.RowsAffected
+		return res.Error
+	})
+	return rows, err
+}
+
+// PersistFrame — PersistFunc для кадров клиентов (kind MessageKindData). В закрытую сессию
+// кадр не сохраняется: ErrSessionClosed.
+func (s *DataService) PersistFrame(sessionID, userID uuid.UUID, payload []byte) (int64, error) {
+	closed, err := s.sessionClosed(context.Background(), sessionID)
+	if err != nil {
+		return 0, err
+	}
+	if closed {
+		return 0, ErrSessionClosed
+	}
+	msg, err := s.AppendMessage(sessionID, userID, MessageKindData, datatypes.JSON(payload))
+	if err != nil {
+		return 0, err
+	}
+	return msg.Seq, nil
+}
+
+// MessagesAfter возвращает сообщения сессии с номером больше afterSeq по возрастанию номера (не больше limit).
+func (s *DataService) MessagesAfter(ctx context.Context, sessionID uuid.UUID, afterSeq int64, limit int) ([]model.ChannelMessage, error) {
+	return s.MessagesMatching(ctx, MessageFilter{SessionID: sessionID}, afterSeq, limit)
+}
+
+// MessageFilter — отбор сообщений истории: SessionID uuid.Nil — все сессии, Kinds пусто — все виды.
+type MessageFilter struct {
+	SessionID uuid.UUID
+	Kinds     []string
+}
+
+// MessagesMatching — как MessagesAfter, но по фильтру f (подписки Subscribe).
+func (s *DataService) MessagesMatching(ctx context.Context, f MessageFilter, afterSeq int64, limit int) ([]model.ChannelMessage, error) {
+	q := s.db.WithContext(ctx).Where("seq > ?", afterSeq)
+	if f.SessionID != uuid.Nil {
+		q = q.Where("session_id = ?", f.SessionID)
+	}
+	if len(f.Kinds) > 0 {
+		q = q.Where("kind IN ?", f.Kinds)
+	}
+	var list []model.ChannelMessage
+	err := q.Order("seq ASC").Limit(limit).Find(&list).Error
+	return list, err
 }
 
 func (s *DataService) GetHistory(sessionID uuid.UUID, limit int) ([]model.ChannelMessage, error) {
@@ -47,17 +185,210 @@ func (s *DataService) GetHistory(sessionID uuid.UUID, limit int) ([]model.Channe
 	return list, err
 }
 
+// Upload — входные данные загрузки файла, общие для gRPC и multipart.
+type Upload struct {
+	SessionID    uuid.UUID
+	UserID       uuid.UUID
+	Filename     string
+	DeclaredType string // Content-Type от клиента; сверяется с сигнатурой содержимого
+	Size         int64
+	Content      io.Reader
+	// ExpectedSHA256 — контрольная сумма, заявленная клиентом (hex); пусто — не проверяется.
+	ExpectedSHA256 string
+	// MaxSize — лимит размера вместо лимита политики (прямые загрузки); 0 — лимит политики.
+	MaxSize int64
+}
+
+// UploadFile — единый конвейер загрузки: членство в сессии (зрителям загрузка запрещена), размер, имя, определение типа по сигнатуре,
+// сверка с заявленным типом и allow/deny списки политики, затем SHA-256, удаление метаданных
+// изображений (если включено) и запись содержимого в хранилище по хешу
+// (повторная загрузка того же содержимого только добавляет ссылку).
+func (s *DataService) UploadFile(ctx context.Context, u Upload) (*model.ChannelFile, error) {
+	if err := s.checkWriter(ctx, u.SessionID, u.UserID); err != nil {
+		return nil, err
+	}
+	policy := s.policy
+	if u.MaxSize > 0 {
+		policy.MaxSizeBytes = u.MaxSize
+	}
+	if err := policy.CheckSize(u.Size); err != nil {
+		return nil, err
+	}
+	if u.Content == nil {
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidFile)
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(u.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+	detected := DetectContentType(head[:n])
+	if err := s.policy.CheckType(detected); err != nil {
+		return nil, err
+	}
+	contentType, err := resolveContentType(u.DeclaredType, detected)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.CheckType(contentType); err != nil {
+		return nil, err
+	}
+	tmp, digest, size, err := spool(io.MultiReader(bytes.NewReader(head[:n]), u.Content), policy.Limit())
+	if err != nil {
+		return nil, err
+	}
+	defer discardSpool(tmp)
+	if size != u.Size {
+		return nil, fmt.Errorf("%w: size mismatch: declared %d, received %d", ErrInvalidFile, u.Size, size)
+	}
+	if u.ExpectedSHA256 != "" && !strings.EqualFold(u.ExpectedSHA256, digest) {
+		return nil, fmt.Errorf("%w: declared sha256 %s, received %s", ErrChecksumMismatch, u.ExpectedSHA256, digest)
+	}
+	f := &model.ChannelFile{
+		SessionID:   u.SessionID,
+		UserID:      u.UserID,
+		Filename:    SanitizeFilename(u.Filename),
+		ContentType: contentType,
+		SizeBytes:   size,
+		SHA256:      digest,
+	}
+	// Исходник с метаданными; сохраняется только при SanitizePolicy.KeepOriginal.
+	var original *os.File
+	var originalDigest string
+	var originalSize int64
+	if s.sanitize.Enabled && metastrip.Supports(contentType) {
+		clean, cleanDigest, cleanSize, err := sanitizeSpool(tmp, contentType)
+		if err != nil {
+			return nil, err
+		}
+		f.MetadataStripped = true
+		if clean != nil {
+			defer discardSpool(clean)
+			if s.sanitize.KeepOriginal {
+				original, originalDigest, originalSize = tmp, digest, size
+				f.OriginalSHA256 = digest
+			}
+			tmp, f.SHA256, f.SizeBytes = clean, cleanDigest, cleanSize
+		}
+	}
+	s.initFileStatus(f)
+	var written blobWrites
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.chargeQuota(tx, u.SessionID, u.UserID, f.SizeBytes); err != nil {
+			return err
+		}
+		blob, err := s.acquireBlob(ctx, tx, &written, f.SHA256, f.SizeBytes, s.putSpool(ctx, tmp))
+		if err != nil {
+			return err
+		}
+		f.StoragePath = blob.StoragePath
+		if original != nil {
+			// Исходник в квоту пользователя не входит: его хранение — решение развёртывания.
+			if _, err := s.acquireBlob(ctx, tx, &written, originalDigest, originalSize, s.putSpool(ctx, original)); err != nil {
+				return err
+			}
+		}
+		return s.SaveFile(tx, f, policy.MaxSizeBytes)
+	})
+	s.settleBlobWrites(ctx, written, err == nil)
+	if err != nil {
+		return nil, err
+	}
+	s.announceFile(ctx, f)
+	return f, nil
+}
+
+// initFileStatus задаёт начальные статусы проверки и превью нового файла.
+func (s *DataService) initFileStatus(f *model.ChannelFile) {
+	f.ScanStatus = ScanClean
+	if s.scans != nil {
+		f.ScanStatus = ScanPending
+	}
+	f.PreviewStatus = PreviewNone
+	if s.previews != nil && s.previews.Supports(f.ContentType) {
+		f.PreviewStatus = PreviewPending
+	}
+}
+
+// announceFile рассылает file.uploaded и ставит сохранённый файл в очередь проверки или превью.
+func (s *DataService) announceFile(ctx context.Context, f *model.ChannelFile) {
+	s.emit(ctx, f.SessionID, f.UserID, EventFileUploaded, s.fileEventData(f))
+	switch {
+	case f.ScanStatus == ScanPending:
+		// Превью строятся только после того, как сканер признает файл чистым.
+		s.scans.Enqueue(f.ID)
+	case f.PreviewStatus == PreviewPending:
+		s.previews.Enqueue(f.ID)
+	}
+}
+
+// OpenThumbnail возвращает превью изображения размером не меньше size (или наибольшее из готовых).
+func (s *DataService) OpenThumbnail(ctx context.Context, fileID uuid.UUID, size int) (io.ReadCloser, string, int, error) {
+	if s.previews == nil {
+		return nil, "", 0, ErrPreviewUnavailable
+	}
+	f, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if err := checkScan(f); err != nil {
+		return nil, "", 0, err
+	}
+	return s.previews.Open(ctx, f, size)
+}
+
+// GetFile возвращает метаданные файла.
+func (s *DataService) GetFile(ctx context.Context, fileID uuid.UUID) (*model.ChannelFile, error) {
+	var f model.ChannelFile
+	err := s.db.WithContext(ctx).Where("id = ?", fileID).First(&f).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// OpenFile возвращает метаданные файла и поток его содержимого; поток закрывает вызывающий.
+// Файлы, не прошедшие антивирусную проверку, не выдаются.
+func (s *DataService) OpenFile(ctx context.Context, fileID uuid.UUID) (*model.ChannelFile, io.ReadCloser, error) {
+	f, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkScan(f); err != nil {
+		return nil, nil, err
+	}
+	if f.StoragePath == "" {
+		return nil, nil, ErrFileNotFound
+	}
+	rc, err := s.store.Open(ctx, f.StoragePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, rc, nil
+}
+
 // ValidateFile checks filename, size and storagePath for security (path traversal, size limit).
-func (s *DataService) ValidateFile(filename string, sizeBytes int64, storagePath string) error {
-	if sizeBytes < 0 || sizeBytes > maxFileSizeBytes {
-		return errors.New("file size exceeds limit or invalid")
+// maxSize — effective size limit of the upload path (direct uploads have their own); 0 — policy limit.
+func (s *DataService) ValidateFile(filename string, sizeBytes int64, storagePath string, maxSize int64) error {
+	policy := s.policy
+	if maxSize > 0 {
+		policy.MaxSizeBytes = maxSize
+	}
+	if err := policy.CheckSize(sizeBytes); err != nil {
+		return err
 	}
 	base := filepath.Base(strings.TrimSpace(filename))
 	if base == "" || base == "." || strings.Contains(base, "..") {
-		return errors.New("invalid filename")
+		return fmt.Errorf("%w: invalid filename", ErrInvalidFile)
 	}
 	if len(base) > maxFilenameLen {
-		return errors.New("filename too long")
+		return fmt.Errorf("%w: filename too long", ErrInvalidFile)
 	}
 	if storagePath != "" {
 		clean := filepath.Clean(storagePath)
@@ -71,25 +402,12 @@ func (s *DataService) ValidateFile(filename string, sizeBytes int64, storagePath
 	return nil
 }
 
-func (s *DataService) SaveFile(sessionID, userID uuid.UUID, filename, contentType string, sizeBytes int64, storagePath string) (*model.ChannelFile, error) {
-	if err := s.ValidateFile(filename, sizeBytes, storagePath); err != nil {
-		return nil, err
-	}
-	// Use base name only for storage (path traversal protection).
-	safeName := filepath.Base(strings.TrimSpace(filename))
-	if safeName == "" || safeName == "." {
-		safeName = "file"
-	}
-	if len(safeName) > maxFilenameLen {
-		safeName = safeName[:maxFilenameLen]
-	}
-	f := &model.ChannelFile{
-		SessionID:   sessionID,
-		UserID:      userID,
-		Filename:    safeName,
-		ContentType: contentType,
-		SizeBytes:   sizeBytes,
-		StoragePath: storagePath,
+// SaveFile проверяет и вставляет строку channel_files в транзакции tx; maxSize — лимит размера
+// пути загрузки (0 — лимит политики).
+func (s *DataService) SaveFile(tx *gorm.DB, f *model.ChannelFile, maxSize int64) error {
+	if err := s.ValidateFile(f.Filename, f.SizeBytes, f.StoragePath, maxSize); err != nil {
+		return err
 	}
-	return f, s.db.Create(f).Error
+	f.Filename = SanitizeFilename(f.Filename)
+	return tx.Create(f).Error
 }
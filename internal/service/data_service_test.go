@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB — gorm без подключения к БД: запросы только строятся.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSaveFileUsesUploadPathLimit(t *testing.T) {
+	db := dryRunDB(t)
+	policy := DefaultFilePolicy()
+	policy.MaxSizeBytes = 10 << 20
+	s := NewDataService(db, nil, policy)
+	s.directMax = 1 << 30
+
+	cases := []struct {
+		name    string
+		size    int64
+		maxSize int64
+		wantErr error
+	}{
+		{name: "direct upload above policy limit", size: 200 << 20, maxSize: s.directMax},
+		{name: "direct upload above its own limit", size: s.directMax + 1, maxSize: s.directMax, wantErr: ErrFileTooLarge},
+		{name: "streaming upload above policy limit", size: 200 << 20, maxSize: 0, wantErr: ErrFileTooLarge},
+		{name: "empty file", size: 0, maxSize: s.directMax, wantErr: ErrInvalidFile},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &model.ChannelFile{
+				SessionID:   uuid.New(),
+				UserID:      uuid.New(),
+				Filename:    "video.mp4",
+				ContentType: "video/mp4",
+				SizeBytes:   tc.size,
+				StoragePath: "blobs/ab/abcdef",
+			}
+			err := s.SaveFile(db, f, tc.maxSize)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("SaveFile: %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("SaveFile error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
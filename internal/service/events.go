@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+)
+
+// MessageKindData — вид (channel_messages.kind) кадров клиентов.
+const MessageKindData = "data"
+
+// Типы служебных событий сессии; тип события записывается в channel_messages.kind.
+const (
+	EventFileUploaded    = "file.uploaded"
+	EventFileDeleted     = "file.deleted"
+	EventFileScanned     = "file.scanned"
+	EventFileQuarantined = "file.quarantined"
+	EventMessageDeleted  = "message.deleted"
+)
+
+// EventSessionClosed — сессия закрыта (CloseSession): рассылается перед отключением участников,
+// в историю не пишется (закрытие записано в sessions).
+const EventSessionClosed = "session.closed"
+
+// События присутствия и ошибки: только рассылаются подключённым клиентам, в историю не пишутся.
+const (
+	EventPresenceState  = "presence.state"  // новому клиенту: все подключённые к сессии
+	EventPresenceJoined = "presence.joined" // остальным: пользователь подключился
+	EventPresenceLeft   = "presence.left"
+	EventError          = "error" // ответ клиенту на отклонённый кадр
+)
+
+// Коды события error: кадр зрителя (viewer), участника под mute или сверх лимита отклонён.
+const (
+	ErrorCodeReadOnly    = "read_only"
+	ErrorCodeMuted       = "muted"
+	ErrorCodeRateLimited = "rate_limited"
+)
+
+// PresenceData — подключённый пользователь и его роль в сессии.
+type PresenceData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+// PresenceState — данные presence.state.
+type PresenceState struct {
+	Members []PresenceData `json:"members"`
+}
+
+// ErrorData — данные события error.
+type ErrorData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MessageDeletedData — данные события message.deleted.
+type MessageDeletedData struct {
+	MessageID uuid.UUID `json:"message_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
+	DeletedBy uuid.UUID `json:"deleted_by"`
+}
+
+// Event — служебное событие, рассылаемое участникам сессии поверх обычных сообщений.
+type Event struct {
+	Type      string      `json:"type"`
+	SessionID uuid.UUID   `json:"session_id"`
+	SenderID  *uuid.UUID  `json:"sender_id,omitempty"` // отправитель PublishMessage; SystemSenderID — система
+	Data      interface{} `json:"data"`
+	Time      time.Time   `json:"time"`
+	// Seq — номер сохранённого события в channel_messages; 0 — событие не сохраняется.
+	Seq int64 `json:"seq,omitempty"`
+}
+
+// FileEventData — данные событий file.*.
+type FileEventData struct {
+	FileID      uuid.UUID  `json:"file_id"`
+	Filename    string     `json:"filename"`
+	SizeBytes   int64      `json:"size_bytes"`
+	ContentType string     `json:"content_type"`
+	SHA256      string     `json:"sha256,omitempty"`
+	UploaderID  uuid.UUID  `json:"uploader_id"`
+	URL         string     `json:"url"`
+	URLExpires  *time.Time `json:"url_expires_at,omitempty"`
+	ScanStatus  string     `json:"scan_status,omitempty"`
+	Signature   string     `json:"signature,omitempty"`
+	DeletedBy   *uuid.UUID `json:"deleted_by,omitempty"`
+}
+
+// fileEventData — данные события файла со ссылкой FileURL (подписанной, если задан FILE_URL_KEYS).
+func (s *DataService) fileEventData(f *model.ChannelFile) FileEventData {
+	url, expires := s.FileURL(f.ID, 0, nil)
+	data := FileEventData{
+		FileID:      f.ID,
+		Filename:    f.Filename,
+		SizeBytes:   f.SizeBytes,
+		ContentType: f.ContentType,
+		SHA256:      f.SHA256,
+		UploaderID:  f.UserID,
+		URL:         url,
+		ScanStatus:  f.ScanStatus,
+	}
+	if !expires.IsZero() {
+		data.URLExpires = &expires
+	}
+	return data
+}
+
+// emit сохраняет событие в историю сессии (channel_messages, kind = тип события, user_id = actorID)
+// и рассылает его подключённым участникам. Ошибки записи логируются: событие всё равно доставляется.
+func (s *DataService) emit(ctx context.Context, sessionID, actorID uuid.UUID, eventType string, data interface{}) {
+	ev := Event{Type: eventType, SessionID: sessionID, Data: data, Time: time.Now().UTC()}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("event %s: %v", eventType, err)
+		return
+	}
+	msg := &model.ChannelMessage{
+		SessionID: sessionID,
+		UserID:    actorID,
+		Kind:      eventType,
+		Payload:   payload,
+	}
+	if _, err := s.insertMessage(ctx, msg); err != nil {
+		log.Printf("event %s: persist: %v", eventType, err)
+	}
+	ev.Seq = msg.Seq
+	if s.events != nil {
+		s.events.Publish(ev)
+	}
+}
+
+// EventPublisher доставляет служебные события участникам сессии.
+type EventPublisher interface {
+	Publish(ev Event)
+}
+
+// Publish рассылает событие всем подключённым участникам сессии.
+func (h *DataHub) Publish(ev Event) {
+	h.publishExcept(ev, nil)
+}
+
+func (h *DataHub) publishExcept(ev Event, excludeUserID *uuid.UUID) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	msg, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("event %s: %v", ev.Type, err)
+		return
+	}
+	h.broadcast(ev.SessionID, Frame{Seq: ev.Seq, Kind: ev.Type, Data: msg}, excludeUserID)
+}
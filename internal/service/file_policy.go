@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen — сколько первых байт содержимого используется для определения типа.
+const sniffLen = 512
+
+const defaultMaxFileSizeBytes = 50 << 20 // 50 MiB
+const maxFilenameLen = 255
+
+// Ошибки проверки загружаемого файла; транспорт отображает их в 400/415 и InvalidArgument.
+var (
+	ErrInvalidFile         = errors.New("invalid file")
+	ErrFileTooLarge        = errors.New("file size exceeds limit")
+	ErrContentTypeMismatch = errors.New("content type mismatch")
+	ErrFileTypeNotAllowed  = errors.New("file type not allowed")
+	ErrChecksumMismatch    = errors.New("checksum mismatch")
+)
+
+// DefaultDeniedTypes — исполняемые файлы и активное содержимое (в том числе SVG и XHTML со скриптами),
+// запрещённые всегда: FILE_DENIED_TYPES дополняет этот список.
+var DefaultDeniedTypes = []string{
+	"application/x-executable",
+	"application/x-sharedlib",
+	"application/vnd.microsoft.portable-executable",
+	"application/x-mach-binary",
+	"application/java-vm",
+	"application/java-archive",
+	"text/x-shellscript",
+	"text/html",
+	"application/xhtml+xml",
+	"image/svg+xml",
+}
+
+// FilePolicy — правила приёма файлов: лимит размера и списки разрешённых/запрещённых MIME-типов.
+// Шаблоны вида "image/*" совпадают с любым подтипом. Пустой Allowed разрешает всё, что не запрещено.
+type FilePolicy struct {
+	MaxSizeBytes int64
+	Allowed      []string
+	Denied       []string
+}
+
+// DefaultFilePolicy — 50 MiB, все типы кроме DefaultDeniedTypes.
+func DefaultFilePolicy() FilePolicy {
+	return FilePolicy{MaxSizeBytes: defaultMaxFileSizeBytes, Denied: DefaultDeniedTypes}
+}
+
+// Limit возвращает максимальный размер файла в байтах.
+func (p FilePolicy) Limit() int64 {
+	if p.MaxSizeBytes <= 0 {
+		return defaultMaxFileSizeBytes
+	}
+	return p.MaxSizeBytes
+}
+
+// CheckSize проверяет размер файла против лимита политики.
+func (p FilePolicy) CheckSize(sizeBytes int64) error {
+	limit := p.Limit()
+	if sizeBytes <= 0 {
+		return fmt.Errorf("%w: empty file", ErrInvalidFile)
+	}
+	if sizeBytes > limit {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrFileTooLarge, sizeBytes, limit)
+	}
+	return nil
+}
+
+// CheckType проверяет MIME-тип по deny-, затем по allow-списку.
+func (p FilePolicy) CheckType(contentType string) error {
+	mt := mediaType(contentType)
+	if matchType(p.Denied, mt) {
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mt)
+	}
+	if len(p.Allowed) > 0 && !matchType(p.Allowed, mt) {
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mt)
+	}
+	return nil
+}
+
+func matchType(patterns []string, mt string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+		case p == "*" || p == "*/*" || p == mt:
+			return true
+		case strings.HasSuffix(p, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(p, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType возвращает тип без параметров в нижнем регистре ("text/plain; charset=utf-8" -> "text/plain").
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	mt, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// execSignatures — сигнатуры исполняемых форматов, которые http.DetectContentType не различает.
+var execSignatures = []struct {
+	magic []byte
+	mime  string
+}{
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCE}, "application/x-mach-binary"},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCF}, "application/x-mach-binary"},
+	{[]byte{0xCE, 0xFA, 0xED, 0xFE}, "application/x-mach-binary"},
+	{[]byte{0xCF, 0xFA, 0xED, 0xFE}, "application/x-mach-binary"},
+	{[]byte{0xCA, 0xFE, 0xBA, 0xBE}, "application/java-vm"},
+	{[]byte("#!"), "text/x-shellscript"},
+	{[]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, "application/x-ole-storage"},
+}
+
+// DetectContentType определяет MIME-тип по сигнатуре (magic bytes) первых байт содержимого.
+func DetectContentType(head []byte) string {
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+	for _, sig := range execSignatures {
+		if bytes.HasPrefix(head, sig.magic) {
+			return sig.mime
+		}
+	}
+	if isPE(head) {
+		return "application/vnd.microsoft.portable-executable"
+	}
+	return mediaType(http.DetectContentType(head))
+}
+
+// isPE проверяет заголовок PE: "MZ", по смещению e_lfanew (uint32 LE в 0x3C) — "PE\0\0".
+// Одного "MZ" недостаточно: с этих байт начинаются и обычные текстовые файлы.
+func isPE(head []byte) bool {
+	if len(head) < 0x40 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	off := int64(binary.LittleEndian.Uint32(head[0x3C:]))
+	return off+4 <= int64(len(head)) && bytes.Equal(head[off:off+4], []byte("PE\x00\x00"))
+}
+
+// refinements — заявленные типы, которые допустимы для более общего обнаруженного типа.
+// Сниффер видит только контейнер (zip, OLE, текст), поэтому уточнение клиента сохраняется.
+var refinements = map[string][]string{
+	"text/plain":                {"text/*", "application/json", "application/xml", "application/x-ndjson", "application/yaml"},
+	"text/xml":                  {"application/xml", "image/svg+xml"},
+	"application/zip":           {"application/vnd.openxmlformats-officedocument.*", "application/vnd.oasis.opendocument.*", "application/epub+zip", "application/java-archive"},
+	"application/x-ole-storage": {"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint", "application/vnd.ms-outlook"},
+}
+
+// resolveContentType сверяет заявленный клиентом тип с обнаруженным и возвращает итоговый тип.
+// Пустой или application/octet-stream заявленный тип не проверяется: берётся обнаруженный.
+func resolveContentType(declared, detected string) (string, error) {
+	declared = mediaType(declared)
+	if declared == "" || declared == "application/octet-stream" || declared == detected {
+		return detected, nil
+	}
+	if detected == "application/octet-stream" {
+		// Сигнатура неизвестна: заявленному типу нельзя доверять, сохраняем как бинарный.
+		return detected, nil
+	}
+	for _, p := range refinements[detected] {
+		if p == declared || (strings.HasSuffix(p, "*") && strings.HasPrefix(declared, strings.TrimSuffix(p, "*"))) {
+			return declared, nil
+		}
+	}
+	return "", fmt.Errorf("%w: declared %s, detected %s", ErrContentTypeMismatch, declared, detected)
+}
+
+// SanitizeFilename оставляет только базовое имя файла (защита от path traversal) и обрезает его до 255 байт.
+func SanitizeFilename(name string) string {
+	base := filepath.Base(strings.TrimSpace(strings.ReplaceAll(name, "\\", "/")))
+	if base == "" || base == "." || base == "/" || strings.Contains(base, "..") {
+		return "file"
+	}
+	if len(base) > maxFilenameLen {
+		base = base[:maxFilenameLen]
+	}
+	return base
+}
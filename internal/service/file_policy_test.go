@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func peHeader(lfanew uint32) []byte {
+	head := make([]byte, 256)
+	copy(head, "MZ")
+	binary.LittleEndian.PutUint32(head[0x3C:], lfanew)
+	if int(lfanew)+4 <= len(head) {
+		copy(head[lfanew:], "PE\x00\x00")
+	}
+	return head
+}
+
+func TestDetectContentTypeExecutables(t *testing.T) {
+	cases := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{name: "pe", head: peHeader(0x80), want: "application/vnd.microsoft.portable-executable"},
+		{name: "mz text", head: []byte("MZ is the postal code prefix in this plain text note"), want: "text/plain"},
+		{name: "mz with e_lfanew outside head", head: peHeader(0xFFFFFFF0), want: "application/octet-stream"},
+		{name: "elf", head: []byte("\x7fELF\x02\x01\x01"), want: "application/x-executable"},
+		{name: "shell", head: []byte("#!/bin/sh\necho hi\n"), want: "text/x-shellscript"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectContentType(tc.head); got != tc.want {
+				t.Fatalf("DetectContentType = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPolicyRejectsActiveContent(t *testing.T) {
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+	cases := []struct {
+		name     string
+		declared string
+		head     []byte
+		wantErr  error
+	}{
+		{name: "svg declared over xml", declared: "image/svg+xml", head: svg, wantErr: ErrFileTypeNotAllowed},
+		{name: "html", declared: "", head: []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"), wantErr: ErrFileTypeNotAllowed},
+		{name: "pe", declared: "application/octet-stream", head: peHeader(0x80), wantErr: ErrFileTypeNotAllowed},
+		{name: "plain text", declared: "text/plain", head: []byte("hello"), wantErr: nil},
+	}
+	policy := DefaultFilePolicy()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			detected := DetectContentType(tc.head)
+			err := policy.CheckType(detected)
+			if err == nil {
+				var ct string
+				if ct, err = resolveContentType(tc.declared, detected); err == nil {
+					err = policy.CheckType(ct)
+				}
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/urlsign"
+)
+
+const (
+	defaultFileURLTTL = 15 * time.Minute
+	maxFileURLTTL     = 7 * 24 * time.Hour
+)
+
+// SetURLSigner включает подписанные ссылки на файлы: без действительной подписи содержимое не выдаётся.
+// ttl — срок ссылок по умолчанию, maxTTL — наибольший срок, который можно запросить.
+func (s *DataService) SetURLSigner(signer *urlsign.Signer, ttl, maxTTL time.Duration) {
+	if ttl <= 0 {
+		ttl = defaultFileURLTTL
+	}
+	if maxTTL <= 0 {
+		maxTTL = maxFileURLTTL
+	}
+	s.signer, s.urlTTL, s.urlMaxTTL = signer, ttl, maxTTL
+}
+
+// FileURL возвращает ссылку на содержимое файла. С подписью ссылка действует ttl (0 — срок по умолчанию)
+// и, если задан userID, только для этого пользователя. Без подписывателя — постоянный путь и нулевой срок.
+func (s *DataService) FileURL(fileID uuid.UUID, ttl time.Duration, userID *uuid.UUID) (string, time.Time) {
+	path := "/data/file/" + fileID.String()
+	if s.signer == nil {
+		return path, time.Time{}
+	}
+	if ttl <= 0 {
+		ttl = s.urlTTL
+	}
+	if ttl > s.urlMaxTTL {
+		ttl = s.urlMaxTTL
+	}
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	return path + "?" + s.signer.Sign(fileID, expires, userID).Encode(), expires
+}
+
+// IssueFileURL проверяет, что файл существует, а пользователь состоит в его сессии, и выдаёт ссылку (см. FileURL).
+func (s *DataService) IssueFileURL(ctx context.Context, fileID uuid.UUID, ttl time.Duration, userID *uuid.UUID) (string, time.Time, error) {
+	f, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := s.CheckMember(ctx, f.SessionID, userID); err != nil {
+		return "", time.Time{}, err
+	}
+	u, exp := s.FileURL(fileID, ttl, userID)
+	return u, exp, nil
+}
+
+// VerifyFileURL проверяет подпись ссылки на файл. callerID — пользователь запроса (nil — неизвестен);
+// ссылка, привязанная к пользователю, принимается только от него. signed — подпись проверена;
+// без подписывателя проверка не выполняется и signed = false.
+func (s *DataService) VerifyFileURL(fileID uuid.UUID, q url.Values, callerID *uuid.UUID) (signed bool, err error) {
+	if s.signer == nil {
+		return false, nil
+	}
+	bound, err := s.signer.Verify(fileID, q, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrForbidden, err)
+	}
+	if bound != nil && (callerID == nil || *callerID != *bound) {
+		return false, fmt.Errorf("%w: link is bound to another user", ErrForbidden)
+	}
+	return true, nil
+}
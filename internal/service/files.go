@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultListFilesLimit = 50
+	maxListFilesLimit     = 200
+	gcBatchSize           = 100
+)
+
+var (
+	// ErrForbidden — у пользователя нет прав на операцию.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidCursor — курсор пагинации повреждён или от другого запроса.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ModeratorResolver сообщает, может ли пользователь модерировать сессию (например, удалять чужие файлы).
+type ModeratorResolver interface {
+	IsModerator(ctx context.Context, sessionID, userID uuid.UUID) (bool, error)
+}
+
+// FileFilter — необязательные фильтры ListFiles.
+type FileFilter struct {
+	UserID      *uuid.UUID
+	ContentType string // точный тип или шаблон "image/*"
+	ScanStatus  string
+}
+
+// ListFiles возвращает файлы сессии от новых к старым и курсор следующей страницы ("" — страниц больше нет).
+func (s *DataService) ListFiles(ctx context.Context, sessionID uuid.UUID, filter FileFilter, cursor string, limit int) ([]model.ChannelFile, string, error) {
+	if limit <= 0 {
+		limit = defaultListFilesLimit
+	}
+	if limit > maxListFilesLimit {
+		limit = maxListFilesLimit
+	}
+	q := s.db.WithContext(ctx).Where("session_id = ?", sessionID)
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	if ct := strings.ToLower(strings.TrimSpace(filter.ContentType)); ct != "" {
+		if prefix, ok := strings.CutSuffix(ct, "*"); ok {
+			q = q.Where("content_type LIKE ?", escapeLike(prefix)+"%")
+		} else {
+			q = q.Where("content_type = ?", ct)
+		}
+	}
+	if filter.ScanStatus != "" {
+		q = q.Where("scan_status = ?", filter.ScanStatus)
+	}
+	if cursor != "" {
+		at, id, err := decodeFileCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		q = q.Where("(created_at, id) < (?, ?)", at, id)
+	}
+	var files []model.ChannelFile
+	if err := q.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&files).Error; err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(files) > limit {
+		files = files[:limit]
+		last := files[len(files)-1]
+		next = encodeFileCursor(last.CreatedAt, last.ID)
+	}
+	return files, next, nil
+}
+
+// DeleteFile мягко удаляет файл. Разрешено загрузившему и модераторам сессии.
+// Содержимое освобождается позже сборщиком мусора (CollectDeletedFiles).
+func (s *DataService) DeleteFile(ctx context.Context, fileID, actorID uuid.UUID) (*model.ChannelFile, error) {
+	f, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if f.UserID != actorID {
+		allowed, err := s.isModerator(ctx, f.SessionID, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: only the uploader or a session moderator can delete a file", ErrForbidden)
+		}
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&model.ChannelFile{}, "id = ?", f.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrFileNotFound
+		}
+		// Квота возвращается сразу, содержимое — после периода ожидания в CollectDeletedFiles.
+		return refundQuota(tx, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := s.fileEventData(f)
+	data.DeletedBy = &actorID
+	s.emit(ctx, f.SessionID, actorID, EventFileDeleted, data)
+	return f, nil
+}
+
+// CollectDeletedFiles окончательно удаляет строки, мягко удалённые раньше olderThan,
+// и снимает их ссылки на содержимое; объекты без ссылок удаляются из хранилища после commit,
+// как и объекты, записанные откатившимися транзакциями (reconcileBlobWrites).
+// Возвращает число обработанных файлов.
+func (s *DataService) CollectDeletedFiles(ctx context.Context, olderThan time.Time) (int, error) {
+	if err := s.purgeReleasedBlobs(ctx); err != nil {
+		return 0, err
+	}
+	if err := s.reconcileBlobWrites(ctx); err != nil {
+		return 0, err
+	}
+	collected := 0
+	for {
+		n := 0
+		var released []string
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			released = released[:0]
+			var files []model.ChannelFile
+			if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", olderThan).
+				Limit(gcBatchSize).Find(&files).Error; err != nil {
+				return err
+			}
+			for _, f := range files {
+				if err := tx.Unscoped().Delete(&model.ChannelFile{}, "id = ?", f.ID).Error; err != nil {
+					return err
+				}
+				for _, digest := range []string{f.SHA256, f.OriginalSHA256} {
+					if digest == "" {
+						continue
+					}
+					last, err := s.releaseBlob(tx, digest)
+					if err != nil {
+						return err
+					}
+					if last {
+						released = append(released, digest)
+					}
+				}
+			}
+			n = len(files)
+			return nil
+		})
+		collected += n
+		if err == nil {
+			for _, digest := range released {
+				if perr := s.purgeBlob(ctx, digest); perr != nil {
+					log.Printf("file gc: blob %s: %v", digest, perr)
+				}
+			}
+		}
+		if err != nil || n < gcBatchSize {
+			return collected, err
+		}
+	}
+}
+
+// purgeReleasedBlobs повторяет удаление содержимого без ссылок, не удавшееся в прошлых проходах.
+func (s *DataService) purgeReleasedBlobs(ctx context.Context) error {
+	var digests []string
+	if err := s.db.WithContext(ctx).Model(&model.FileBlob{}).Where("ref_count <= 0").
+		Limit(gcBatchSize).Pluck("sha256", &digests).Error; err != nil {
+		return err
+	}
+	for _, digest := range digests {
+		if err := s.purgeBlob(ctx, digest); err != nil {
+			log.Printf("file gc: blob %s: %v", digest, err)
+		}
+	}
+	return nil
+}
+
+// FileGC периодически запускает CollectDeletedFiles для файлов, удалённых дольше grace назад,
+// и ExpireUploadSessions для брошенных прямых загрузок.
+func (s *DataService) FileGC(interval, grace time.Duration) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				n, err := s.CollectDeletedFiles(ctx, time.Now().Add(-grace))
+				if err != nil {
+					log.Printf("file gc: %v", err)
+				} else if n > 0 {
+					log.Printf("file gc: collected %d files", n)
+				}
+				if n, err := s.ExpireUploadSessions(ctx); err != nil {
+					log.Printf("file gc: upload sessions: %v", err)
+				} else if n > 0 {
+					log.Printf("file gc: expired %d upload sessions", n)
+				}
+			}
+		}
+	}
+}
+
+func encodeFileCursor(at time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(at.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+func decodeFileCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	ts, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return at, id, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/auth"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Роли участников сессии.
+const (
+	RoleOwner       = "owner"
+	RoleOperator    = "operator"
+	RoleParticipant = "participant"
+	RoleViewer      = "viewer"
+)
+
+const (
+	sessionManagerTimeout = 5 * time.Second
+	membershipCacheMax    = 10000
+)
+
+var (
+	// ErrNotMember — пользователь не состоит в сессии.
+	ErrNotMember = fmt.Errorf("%w: not a session member", ErrForbidden)
+	// ErrMemberNotFound — исключаемого пользователя нет в составе сессии.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrInvalidRole — неизвестная роль участника.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrMembershipExternal — состав сессии ведёт внешний сервис, изменить его здесь нельзя.
+	ErrMembershipExternal = errors.New("session membership is managed externally")
+)
+
+// ValidRole сообщает, известна ли роль.
+func ValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleOperator, RoleParticipant, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// MembershipResolver — источник состава сессий: роль пользователя или "" для не-участника.
+type MembershipResolver interface {
+	MemberRole(ctx context.Context, sessionID, userID uuid.UUID) (string, error)
+}
+
+// SetMembership включает проверку членства: подключение, история, загрузка и скачивание
+// доступны только участникам сессии. Без источника членства проверка не выполняется.
+func (s *DataService) SetMembership(r MembershipResolver) { s.members = r }
+
+// MemberRole возвращает роль пользователя в сессии; для не-участника — ErrNotMember.
+// Роль берётся из источника членства, а без него — из токена пользователя запроса
+// (см. auth.Identity.SessionRole); если роль нигде не задана — RoleParticipant.
+func (s *DataService) MemberRole(ctx context.Context, sessionID, userID uuid.UUID) (string, error) {
+	if s.members == nil {
+		if id, ok := auth.FromContext(ctx); ok && id.Subject == userID {
+			if role := id.SessionRole(sessionID); ValidRole(role) {
+				return role, nil
+			}
+		}
+		return RoleParticipant, nil
+	}
+	role, err := s.members.MemberRole(ctx, sessionID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", ErrNotMember
+	}
+	return role, nil
+}
+
+// CheckMember проверяет, что пользователь состоит в сессии. userID nil — пользователь запроса
+// неизвестен: при включённой проверке членства доступ запрещён.
+func (s *DataService) CheckMember(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) error {
+	if s.members == nil {
+		return nil
+	}
+	if userID == nil {
+		return ErrNotMember
+	}
+	_, err := s.MemberRole(ctx, sessionID, *userID)
+	return err
+}
+
+// IsModerator — роль owner или operator.
+func IsModerator(role string) bool {
+	return role == RoleOwner || role == RoleOperator
+}
+
+// isModerator сообщает, может ли пользователь модерировать сессию: через ModeratorResolver,
+// если он задан, иначе по роли (MemberRole).
+func (s *DataService) isModerator(ctx context.Context, sessionID, userID uuid.UUID) (bool, error) {
+	if s.mods != nil {
+		return s.mods.IsModerator(ctx, sessionID, userID)
+	}
+	role, err := s.MemberRole(ctx, sessionID, userID)
+	if errors.Is(err, ErrNotMember) {
+		return false, nil
+	}
+	return IsModerator(role), err
+}
+
+// checkWriter запрещает изменять сессию (загружать файлы) зрителям (viewer), заблокированным и участникам под mute.
+func (s *DataService) checkWriter(ctx context.Context, sessionID, userID uuid.UUID) error {
+	role, err := s.MemberRole(ctx, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if role == RoleViewer {
+		return fmt.Errorf("%w: viewers are read-only", ErrForbidden)
+	}
+	return s.checkRestrictions(ctx, sessionID, userID)
+}
+
+// CheckFileAccess проверяет членство пользователя запроса в сессии файла.
+// Анонимный доступ по подписанной ссылке разрешает только HTTP-скачивание после VerifyFileURL.
+func (s *DataService) CheckFileAccess(ctx context.Context, fileID uuid.UUID, callerID *uuid.UUID) error {
+	if s.members == nil {
+		return nil
+	}
+	f, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	return s.CheckMember(ctx, f.SessionID, callerID)
+}
+
+// localMembers возвращает таблицу session_members, если состав ведётся локально.
+func (s *DataService) localMembers() (*LocalMembers, error) {
+	local, ok := s.members.(*LocalMembers)
+	if !ok {
+		if s.members == nil {
+			return NewLocalMembers(s.db), nil
+		}
+		return nil, ErrMembershipExternal
+	}
+	return local, nil
+}
+
+// canManageMembers: состав меняют владелец и операторы сессии, а также доверенный сервис (trusted,
+// возвращается RoleOwner). В сессию без участников первым владельцем может добавить себя только
+// её создатель (sessions.created_by): тогда возвращается "".
+func (s *DataService) canManageMembers(ctx context.Context, local *LocalMembers, sessionID, actorID uuid.UUID, trusted bool) (string, error) {
+	if trusted {
+		return RoleOwner, nil
+	}
+	role, err := local.MemberRole(ctx, sessionID, actorID)
+	if err != nil {
+		return "", err
+	}
+	if IsModerator(role) {
+		return role, nil
+	}
+	if role == "" {
+		var n int64
+		if err := s.db.WithContext(ctx).Model(&model.SessionMember{}).Where("session_id = ?", sessionID).Count(&n).Error; err != nil {
+			return "", err
+		}
+		if n == 0 {
+			sess, err := s.GetSession(ctx, sessionID)
+			if err != nil && !errors.Is(err, ErrSessionNotFound) {
+				return "", err
+			}
+			if sess != nil && sess.CreatedBy != nil && *sess.CreatedBy == actorID {
+				return "", nil
+			}
+			return "", fmt.Errorf("%w: only the session creator or a trusted service can add the first owner", ErrForbidden)
+		}
+	}
+	return "", fmt.Errorf("%w: only the session owner or an operator can manage members", ErrForbidden)
+}
+
+// AddMember добавляет участника или меняет его роль. Операторы не назначают и не меняют владельцев;
+// trusted — вызывающий — сервис со scope data:members.
+func (s *DataService) AddMember(ctx context.Context, sessionID, actorID, userID uuid.UUID, role string, trusted bool) (*model.SessionMember, error) {
+	if role == "" {
+		role = RoleParticipant
+	}
+	if !ValidRole(role) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+	local, err := s.localMembers()
+	if err != nil {
+		return nil, err
+	}
+	actorRole, err := s.canManageMembers(ctx, local, sessionID, actorID, trusted)
+	if err != nil {
+		return nil, err
+	}
+	if actorRole == "" && (userID != actorID || role != RoleOwner) {
+		return nil, fmt.Errorf("%w: the first member must be the caller as owner", ErrForbidden)
+	}
+	if actorRole == RoleOperator {
+		current, err := local.MemberRole(ctx, sessionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role == RoleOwner || current == RoleOwner {
+			return nil, fmt.Errorf("%w: operators cannot manage owners", ErrForbidden)
+		}
+	}
+	m := &model.SessionMember{SessionID: sessionID, UserID: userID, Role: role, AddedBy: &actorID}
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(m).Error
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RemoveMember исключает участника; покинуть сессию может любой участник.
+func (s *DataService) RemoveMember(ctx context.Context, sessionID, actorID, userID uuid.UUID, trusted bool) error {
+	local, err := s.localMembers()
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		actorRole, err := s.canManageMembers(ctx, local, sessionID, actorID, trusted)
+		if err != nil {
+			return err
+		}
+		if actorRole == RoleOperator {
+			current, err := local.MemberRole(ctx, sessionID, userID)
+			if err != nil {
+				return err
+			}
+			if current == RoleOwner {
+				return fmt.Errorf("%w: operators cannot manage owners", ErrForbidden)
+			}
+		}
+	}
+	res := s.db.WithContext(ctx).Delete(&model.SessionMember{}, "session_id = ? AND user_id = ?", sessionID, userID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// ListMembers возвращает участников сессии; список видят только её участники.
+func (s *DataService) ListMembers(ctx context.Context, sessionID, actorID uuid.UUID) ([]model.SessionMember, error) {
+	local, err := s.localMembers()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckMember(ctx, sessionID, &actorID); err != nil {
+		return nil, err
+	}
+	return local.List(ctx, sessionID)
+}
+
+// LocalMembers — состав сессий из таблицы session_members.
+type LocalMembers struct {
+	db *gorm.DB
+}
+
+// NewLocalMembers создаёт источник членства на таблице session_members.
+func NewLocalMembers(db *gorm.DB) *LocalMembers {
+	return &LocalMembers{db: db}
+}
+
+func (m *LocalMembers) MemberRole(ctx context.Context, sessionID, userID uuid.UUID) (string, error) {
+	var member model.SessionMember
+	err := m.db.WithContext(ctx).Where("session_id = ? AND user_id = ?", sessionID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// List возвращает участников сессии в порядке добавления.
+func (m *LocalMembers) List(ctx context.Context, sessionID uuid.UUID) ([]model.SessionMember, error) {
+	var list []model.SessionMember
+	err := m.db.WithContext(ctx).Where("session_id = ?", sessionID).Order("created_at ASC, user_id ASC").Find(&list).Error
+	return list, err
+}
+
+// SessionManagerMembers запрашивает состав сессии у session-manager:
+// GET {baseURL}/sessions/{session_id}/participants/{user_id} → 200 {"role": "..."} или 404.
+// Ответы кэшируются на cacheTTL, поэтому исключение участника вступает в силу с этой задержкой.
+type SessionManagerMembers struct {
+	baseURL  string
+	token    string
+	cacheTTL time.Duration
+	client   *http.Client
+
+	mu    sync.Mutex
+	cache map[[2]uuid.UUID]cachedRole
+}
+
+type cachedRole struct {
+	role    string
+	expires time.Time
+}
+
+// NewSessionManagerMembers создаёт источник членства; token передаётся как Bearer (пусто — без авторизации).
+func NewSessionManagerMembers(baseURL, token string, cacheTTL time.Duration) *SessionManagerMembers {
+	return &SessionManagerMembers{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		cacheTTL: cacheTTL,
+		client:   &http.Client{Timeout: sessionManagerTimeout},
+		cache:    map[[2]uuid.UUID]cachedRole{},
+	}
+}
+
+func (m *SessionManagerMembers) MemberRole(ctx context.Context, sessionID, userID uuid.UUID) (string, error) {
+	key := [2]uuid.UUID{sessionID, userID}
+	now := time.Now()
+	m.mu.Lock()
+	c, ok := m.cache[key]
+	m.mu.Unlock()
+	if ok && now.Before(c.expires) {
+		return c.role, nil
+	}
+
+	u := m.baseURL + "/sessions/" + url.PathEscape(sessionID.String()) + "/participants/" + url.PathEscape(userID.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("session-manager: %w", err)
+	}
+	defer resp.Body.Close()
+	role := ""
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var out struct {
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return "", fmt.Errorf("session-manager: %w", err)
+		}
+		// Роли, неизвестные этому сервису, получают права обычного участника.
+		role = out.Role
+		if !ValidRole(role) {
+			role = RoleParticipant
+		}
+	case http.StatusNotFound:
+	default:
+		return "", fmt.Errorf("session-manager: unexpected status %s", resp.Status)
+	}
+	if m.cacheTTL > 0 {
+		m.mu.Lock()
+		if len(m.cache) >= membershipCacheMax {
+			m.cache = map[[2]uuid.UUID]cachedRole{}
+		}
+		m.cache[key] = cachedRole{role: role, expires: now.Add(m.cacheTTL)}
+		m.mu.Unlock()
+	}
+	return role, nil
+}
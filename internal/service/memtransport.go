@@ -0,0 +1,88 @@
+package service
+
+import "sync"
+
+// MemTransport — Transport в памяти для детерминированных тестов DataHub без сети: сторона клиента
+// пишет кадры через Deliver и читает отправленные ему из Sent.
+type MemTransport struct {
+	in   chan []byte
+	out  chan Frame
+	done chan struct{}
+	once sync.Once
+
+	mu     sync.Mutex
+	closed CloseInfo
+}
+
+// NewMemTransport создаёт соединение с очередями на buffer кадров в каждую сторону.
+func NewMemTransport(buffer int) *MemTransport {
+	return &MemTransport{
+		in:   make(chan []byte, buffer),
+		out:  make(chan Frame, buffer),
+		done: make(chan struct{}),
+	}
+}
+
+// Send ставит кадр в Sent; при заполненной очереди ждёт, пока клиент его прочитает.
+func (t *MemTransport) Send(f Frame) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+	select {
+	case t.out <- f:
+		return nil
+	case <-t.done:
+		return ErrTransportClosed
+	}
+}
+
+// Receive возвращает следующий кадр, переданный через Deliver.
+func (t *MemTransport) Receive() ([]byte, error) {
+	select {
+	case p := <-t.in:
+		return p, nil
+	case <-t.done:
+		return nil, ErrTransportClosed
+	}
+}
+
+// Close закрывает соединение; повторные вызовы ничего не меняют.
+func (t *MemTransport) Close(code int, reason string) error {
+	t.once.Do(func() {
+		t.mu.Lock()
+		t.closed = CloseInfo{Code: code, Reason: reason}
+		t.mu.Unlock()
+		close(t.done)
+	})
+	return nil
+}
+
+// Deliver передаёт кадр от клиента серверу (его прочитает Receive).
+func (t *MemTransport) Deliver(payload []byte) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+	select {
+	case t.in <- payload:
+		return nil
+	case <-t.done:
+		return ErrTransportClosed
+	}
+}
+
+// Sent — кадры, отправленные клиенту.
+func (t *MemTransport) Sent() <-chan Frame { return t.out }
+
+// Done закрывается при закрытии соединения.
+func (t *MemTransport) Done() <-chan struct{} { return t.done }
+
+// CloseInfo — код и причина закрытия после Done (код 0 — закрыто без кода).
+func (t *MemTransport) CloseInfo() CloseInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
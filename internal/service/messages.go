@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrMessageNotFound — сообщения нет в channel_messages.
+var ErrMessageNotFound = errors.New("message not found")
+
+// DeleteMessage удаляет сообщение из истории сессии и рассылает message.deleted.
+// Разрешено автору и модераторам сессии; служебные события (kind с точкой, например file.uploaded) не удаляются.
+func (s *DataService) DeleteMessage(ctx context.Context, messageID, actorID uuid.UUID) (*model.ChannelMessage, error) {
+	var msg model.ChannelMessage
+	err := s.db.WithContext(ctx).Where("id = ?", messageID).First(&msg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(msg.Kind, ".") {
+		return nil, fmt.Errorf("%w: service events cannot be deleted", ErrForbidden)
+	}
+	if err := s.CheckMember(ctx, msg.SessionID, &actorID); err != nil {
+		return nil, err
+	}
+	if msg.UserID != actorID {
+		allowed, err := s.isModerator(ctx, msg.SessionID, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: only the author or a session moderator can delete a message", ErrForbidden)
+		}
+	}
+	res := s.db.WithContext(ctx).Delete(&model.ChannelMessage{}, "id = ?", msg.ID)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrMessageNotFound
+	}
+	s.emit(ctx, msg.SessionID, actorID, EventMessageDeleted, MessageDeletedData{
+		MessageID: msg.ID,
+		AuthorID:  msg.UserID,
+		DeletedBy: actorID,
+	})
+	return &msg, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Меры модерации; значение — model.ModerationAction.Action и суффикс события moderation.*.
+const (
+	ModerationKick = "kick"
+	ModerationMute = "mute"
+	ModerationBan  = "ban"
+	// ModerationCloseSession — закрытие сессии (CloseSession): отключаются все участники.
+	ModerationCloseSession = "close_session"
+)
+
+// Коды закрытия WebSocket для исключённых участников (диапазон приложений 4000–4999).
+const (
+	CloseKicked = 4001
+	CloseBanned = 4003
+)
+
+const maxModerationReason = 500
+
+var (
+	// ErrBanned — пользователь заблокирован в сессии.
+	ErrBanned = fmt.Errorf("%w: banned from the session", ErrForbidden)
+	// ErrMuted — пользователю временно запрещено писать в сессию.
+	ErrMuted = fmt.Errorf("%w: muted in the session", ErrForbidden)
+	// ErrInvalidDuration — недопустимый срок меры.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// ModerationNotice — применённая мера, доставляемая подключённым клиентам на всех экземплярах.
+type ModerationNotice struct {
+	ActionID  uuid.UUID  `json:"action_id"`
+	Action    string     `json:"action"`
+	SessionID uuid.UUID  `json:"session_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ActorID   uuid.UUID  `json:"actor_id"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ModerationBus доставляет меры модерации до DataConn на всех экземплярах сервиса.
+type ModerationBus interface {
+	Publish(ctx context.Context, n ModerationNotice) error
+}
+
+// SetModerationBus задаёт доставку мер модерации; без неё меры применяются только к
+// подключениям этого экземпляра (если EventPublisher — DataHub).
+func (s *DataService) SetModerationBus(b ModerationBus) { s.moderation = b }
+
+// KickParticipant отключает участника от сессии; повторное подключение не запрещено.
+func (s *DataService) KickParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, reason string) (*model.ModerationAction, error) {
+	return s.moderate(ctx, sessionID, actorID, targetID, ModerationKick, 0, reason)
+}
+
+// MuteParticipant запрещает участнику писать в сессию на срок d (сообщения и загрузки).
+func (s *DataService) MuteParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, d time.Duration, reason string) (*model.ModerationAction, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("%w: mute requires a positive duration", ErrInvalidDuration)
+	}
+	return s.moderate(ctx, sessionID, actorID, targetID, ModerationMute, d, reason)
+}
+
+// BanParticipant отключает участника и запрещает повторное подключение на срок d (0 — бессрочно).
+func (s *DataService) BanParticipant(ctx context.Context, sessionID, actorID, targetID uuid.UUID, d time.Duration, reason string) (*model.ModerationAction, error) {
+	if d < 0 {
+		return nil, fmt.Errorf("%w: ban duration must not be negative", ErrInvalidDuration)
+	}
+	return s.moderate(ctx, sessionID, actorID, targetID, ModerationBan, d, reason)
+}
+
+// moderate проверяет права (модератор; к владельцу меры применяет только владелец), записывает
+// ограничение и журнал в одной транзакции и рассылает меру подключённым клиентам.
+func (s *DataService) moderate(ctx context.Context, sessionID, actorID, targetID uuid.UUID, action string, d time.Duration, reason string) (*model.ModerationAction, error) {
+	if actorID == targetID {
+		return nil, fmt.Errorf("%w: cannot moderate yourself", ErrForbidden)
+	}
+	actorRole, err := s.MemberRole(ctx, sessionID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.isModerator(ctx, sessionID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: only session moderators can %s participants", ErrForbidden, action)
+	}
+	targetRole, err := s.MemberRole(ctx, sessionID, targetID)
+	if err != nil && !errors.Is(err, ErrNotMember) {
+		return nil, err
+	}
+	if targetRole == RoleOwner && actorRole != RoleOwner {
+		return nil, fmt.Errorf("%w: only an owner can %s an owner", ErrForbidden, action)
+	}
+	if len(reason) > maxModerationReason {
+		reason = reason[:maxModerationReason]
+	}
+	rec := &model.ModerationAction{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TargetID:  targetID,
+		ActorID:   actorID,
+		Action:    action,
+		Reason:    reason,
+	}
+	if d > 0 {
+		exp := time.Now().Add(d).UTC()
+		rec.ExpiresAt = &exp
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if action != ModerationKick {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "session_id"}, {Name: "user_id"}, {Name: "kind"}},
+				DoUpdates: clause.AssignmentColumns([]string{"expires_at", "reason", "created_by", "created_at"}),
+			}).Create(&model.SessionRestriction{
+				SessionID: sessionID,
+				UserID:    targetID,
+				Kind:      action,
+				ExpiresAt: rec.ExpiresAt,
+				Reason:    reason,
+				CreatedBy: actorID,
+				CreatedAt: time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(rec).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	notice := ModerationNotice{
+		ActionID:  rec.ID,
+		Action:    action,
+		SessionID: sessionID,
+		UserID:    targetID,
+		ActorID:   actorID,
+		Reason:    reason,
+		ExpiresAt: rec.ExpiresAt,
+	}
+	// Мера уже записана: при сбое доставки подключение будет отклонено при следующем входе
+	// (ban), а mute — при следующей загрузке и переподключении.
+	if err := s.publishModeration(ctx, notice); err != nil {
+		log.Printf("moderation %s %s: deliver: %v", action, rec.ID, err)
+	}
+	return rec, nil
+}
+
+func (s *DataService) publishModeration(ctx context.Context, n ModerationNotice) error {
+	if s.moderation != nil {
+		return s.moderation.Publish(ctx, n)
+	}
+	if hub, ok := s.events.(*DataHub); ok {
+		hub.ApplyModeration(n)
+	}
+	return nil
+}
+
+// JoinRestrictions проверяет ограничения при подключении: ErrSessionClosed для закрытой сессии,
+// ErrBanned для заблокированного, иначе срок действующего mute (нулевое время — не ограничен).
+func (s *DataService) JoinRestrictions(ctx context.Context, sessionID, userID uuid.UUID) (time.Time, error) {
+	if err := s.checkSessionOpen(ctx, sessionID); err != nil {
+		return time.Time{}, err
+	}
+	var list []model.SessionRestriction
+	err := s.db.WithContext(ctx).
+		Where("session_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)", sessionID, userID, time.Now()).
+		Find(&list).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	var mutedUntil time.Time
+	for _, r := range list {
+		switch r.Kind {
+		case ModerationBan:
+			return time.Time{}, ErrBanned
+		case ModerationMute:
+			if r.ExpiresAt != nil {
+				mutedUntil = *r.ExpiresAt
+			}
+		}
+	}
+	return mutedUntil, nil
+}
+
+// checkRestrictions — ErrSessionClosed, ErrBanned или ErrMuted, если пользователю сейчас нельзя писать в сессию.
+func (s *DataService) checkRestrictions(ctx context.Context, sessionID, userID uuid.UUID) error {
+	mutedUntil, err := s.JoinRestrictions(ctx, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(mutedUntil) {
+		return ErrMuted
+	}
+	return nil
+}
+
+// ApplyModeration применяет меру к подключениям этого экземпляра: участникам сессии рассылается
+// событие moderation.<action>, подключение цели закрывается после его доставки (kick, ban)
+// или переводится в режим mute.
+func (h *DataHub) ApplyModeration(n ModerationNotice) {
+	if n.Action == ModerationCloseSession {
+		h.closeSession(n)
+		return
+	}
+	h.Publish(Event{Type: "moderation." + n.Action, SessionID: n.SessionID, Data: n})
+	h.mu.RLock()
+	c := h.sessions[n.SessionID][n.UserID]
+	h.mu.RUnlock()
+	if c == nil {
+		return
+	}
+	switch n.Action {
+	case ModerationKick:
+		c.closeAfterQueued(CloseKicked, "kicked: "+n.Reason)
+	case ModerationBan:
+		c.closeAfterQueued(CloseBanned, "banned: "+n.Reason)
+	case ModerationMute:
+		if n.ExpiresAt != nil {
+			c.MuteUntil(*n.ExpiresAt)
+		}
+	}
+}
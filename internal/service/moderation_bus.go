@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// moderationChannel — канал LISTEN/NOTIFY для мер модерации.
+const moderationChannel = "data_channel_moderation"
+
+// PGModerationBus рассылает меры модерации всем экземплярам через Postgres NOTIFY;
+// каждый экземпляр (включая отправителя) получает их в Run и применяет к своему DataHub.
+type PGModerationBus struct {
+	db  *gorm.DB
+	dsn string
+	hub *DataHub
+}
+
+// NewPGModerationBus создаёт шину; dsn — строка подключения для отдельного LISTEN-соединения.
+func NewPGModerationBus(db *gorm.DB, dsn string, hub *DataHub) *PGModerationBus {
+	return &PGModerationBus{db: db, dsn: dsn, hub: hub}
+}
+
+func (b *PGModerationBus) Publish(ctx context.Context, n ModerationNotice) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", moderationChannel, string(payload)).Error
+}
+
+// Run слушает канал до отмены ctx; соединение восстанавливается автоматически. Первый LISTEN
+// повторяется с задержкой до moderationListenRetryMax, пока не удастся: без него меры не применяются
+// и на этом экземпляре.
+func (b *PGModerationBus) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		listener := pq.NewListener(b.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("moderation: listener: %v", err)
+			}
+		})
+		err := listenContext(ctx, listener)
+		if err == nil {
+			b.consume(ctx, listener)
+			listener.Close()
+			return
+		}
+		listener.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("moderation: listen: %v (retry in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, moderationListenRetryMax)
+	}
+}
+
+// moderationListenRetryMax — предельная задержка между попытками первого LISTEN.
+const moderationListenRetryMax = time.Minute
+
+// listenContext выполняет LISTEN; pq ждёт соединения без ограничения, поэтому ожидание
+// прерывается закрытием listener при отмене ctx.
+func listenContext(ctx context.Context, listener *pq.Listener) error {
+	done := make(chan error, 1)
+	go func() { done <- listener.Listen(moderationChannel) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		listener.Close()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (b *PGModerationBus) consume(ctx context.Context, listener *pq.Listener) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-listener.Notify:
+			if msg == nil {
+				// Переподключение: уведомления за время разрыва потеряны, ban сработает при следующем входе.
+				continue
+			}
+			var n ModerationNotice
+			if err := json.Unmarshal([]byte(msg.Extra), &n); err != nil {
+				log.Printf("moderation: bad notification: %v", err)
+				continue
+			}
+			b.hub.ApplyModeration(n)
+		case <-time.After(90 * time.Second):
+			go func() { _ = listener.Ping() }()
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+)
+
+// Параметры long-polling: ожидание по умолчанию и максимум, размер ответа, срок жизни без опроса.
+const (
+	PollDefaultWait = 25 * time.Second
+	PollMaxWait     = 60 * time.Second
+	pollMaxEvents   = 100
+	pollIdleTTL     = PollMaxWait + 30*time.Second
+)
+
+// CloseReplaced — подключение заменено новым подключением того же пользователя к сессии.
+const CloseReplaced = 4000
+
+// Pollers — подключения long-polling: участник регистрируется в DataHub как обычное подключение
+// (presence, рассылка без отправителя) и живёт между опросами; без опроса дольше pollIdleTTL — отключается.
+type Pollers struct {
+	hub   *DataHub
+	mu    sync.Mutex
+	conns map[pollKey]*PollConn
+}
+
+type pollKey struct {
+	sessionID uuid.UUID
+	userID    uuid.UUID
+}
+
+// NewPollers создаёт реестр подключений long-polling.
+func NewPollers(hub *DataHub) *Pollers {
+	return &Pollers{hub: hub, conns: map[pollKey]*PollConn{}}
+}
+
+// PollConn — подключение long-polling. Выданные кадры хранятся до подтверждения курсором
+// следующего опроса, поэтому потерянный ответ повторяется.
+type PollConn struct {
+	conn *DataConn
+	sem  chan struct{} // один опрос одновременно
+
+	mu         sync.Mutex
+	pending    []PolledFrame
+	next       int64
+	backlogSeq int64 // последний номер, досланный из истории (Replay)
+	lastPoll   time.Time
+}
+
+// PolledFrame — кадр в ответе опроса: ID — курсор (порядковый номер в этом подключении),
+// Seq — номер сообщения в истории (0 — не сохраняется).
+type PolledFrame struct {
+	ID   int64           `json:"id"`
+	Seq  int64           `json:"seq,omitempty"`
+	Data json.RawMessage `json:"data"`
+}
+
+// PollResult — ответ опроса: Cursor передаётся в следующий опрос; Closed — подключение закрыто
+// сервером (kick, ban, вход того же пользователя через другой транспорт), нужно подключиться заново.
+type PollResult struct {
+	Cursor int64         `json:"cursor"`
+	Events []PolledFrame `json:"events"`
+	Closed *CloseInfo    `json:"closed,omitempty"`
+}
+
+// Lookup возвращает действующее подключение пользователя или nil.
+func (p *Pollers) Lookup(sessionID, userID uuid.UUID) *PollConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conns[pollKey{sessionID, userID}]
+}
+
+// Join регистрирует подключение long-polling с ролью role и сроком mute (проверки входа — на вызывающем).
+func (p *Pollers) Join(sessionID, userID uuid.UUID, role string, mutedUntil time.Time) *PollConn {
+	c := p.hub.Register(sessionID, userID, role, nil)
+	c.MuteUntil(mutedUntil)
+	pc := &PollConn{conn: c, sem: make(chan struct{}, 1), lastPoll: time.Now()}
+	p.mu.Lock()
+	old := p.conns[pollKey{sessionID, userID}]
+	p.conns[pollKey{sessionID, userID}] = pc
+	p.mu.Unlock()
+	if old != nil {
+		p.hub.Unregister(old.conn)
+	}
+	return pc
+}
+
+// Poll подтверждает кадры до cursor включительно и возвращает следующие: неподтверждённые сразу,
+// иначе ждёт новых до wait. После закрытия подключения результат содержит Closed, а подключение
+// удаляется из реестра.
+func (p *Pollers) Poll(ctx context.Context, pc *PollConn, cursor int64, wait time.Duration) (PollResult, error) {
+	select {
+	case pc.sem <- struct{}{}:
+		defer func() { <-pc.sem }()
+	case <-ctx.Done():
+		return PollResult{}, ctx.Err()
+	}
+	pc.mu.Lock()
+	pc.lastPoll = time.Now()
+	i := 0
+	for i < len(pc.pending) && pc.pending[i].ID <= cursor {
+		i++
+	}
+	pc.pending = pc.pending[i:]
+	pc.mu.Unlock()
+
+	res, closed := pc.collect(ctx, wait)
+	if closed != nil {
+		p.remove(pc)
+		res.Closed = closed
+	}
+	pc.mu.Lock()
+	pc.lastPoll = time.Now()
+	pc.mu.Unlock()
+	return res, nil
+}
+
+// collect ждёт кадры до wait (если неподтверждённых нет) и забирает уже доступные, не больше pollMaxEvents.
+func (pc *PollConn) collect(ctx context.Context, wait time.Duration) (PollResult, *CloseInfo) {
+	var closed *CloseInfo
+	if pc.pendingLen() == 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case f, ok := <-pc.conn.Frames():
+			if ok {
+				pc.add(f)
+			} else {
+				closed = &CloseInfo{Code: CloseReplaced, Reason: "connected elsewhere"}
+			}
+		case <-pc.conn.Done():
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+drain:
+	for closed == nil && pc.pendingLen() < pollMaxEvents {
+		select {
+		case f, ok := <-pc.conn.Frames():
+			if !ok {
+				closed = &CloseInfo{Code: CloseReplaced, Reason: "connected elsewhere"}
+				break drain
+			}
+			pc.add(f)
+		default:
+			break drain
+		}
+	}
+	select {
+	case <-pc.conn.Done():
+		info := pc.conn.CloseInfo()
+		closed = &info
+	default:
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	res := PollResult{Cursor: pc.next, Events: append([]PolledFrame{}, pc.pending...)}
+	if len(pc.pending) > pollMaxEvents {
+		res.Events = res.Events[:pollMaxEvents]
+		res.Cursor = res.Events[len(res.Events)-1].ID
+	}
+	return res, closed
+}
+
+// Replay ставит в очередь сохранённые сообщения из истории (по возрастанию номера) перед живыми кадрами.
+// Живые кадры с номером не больше последнего из истории пропускаются: номера сессии фиксируются
+// в базе по возрастанию, и такие кадры в истории уже были.
+func (pc *PollConn) Replay(msgs []model.ChannelMessage) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	for _, m := range msgs {
+		pc.next++
+		pc.pending = append(pc.pending, PolledFrame{ID: pc.next, Seq: m.Seq, Data: json.RawMessage(m.Payload)})
+		pc.backlogSeq = m.Seq
+	}
+}
+
+func (pc *PollConn) pendingLen() int {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return len(pc.pending)
+}
+
+func (pc *PollConn) add(f Frame) {
+	data := json.RawMessage(f.Data)
+	if !json.Valid(f.Data) {
+		data, _ = json.Marshal(string(f.Data))
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if f.Seq > 0 && f.Seq <= pc.backlogSeq {
+		return
+	}
+	pc.next++
+	pc.pending = append(pc.pending, PolledFrame{ID: pc.next, Seq: f.Seq, Data: data})
+}
+
+// Leave снимает подключение long-polling (presence.left для остальных участников).
+func (p *Pollers) Leave(pc *PollConn) { p.remove(pc) }
+
+func (p *Pollers) remove(pc *PollConn) {
+	k := pollKey{pc.conn.SessionID, pc.conn.UserID}
+	p.mu.Lock()
+	if p.conns[k] == pc {
+		delete(p.conns, k)
+	}
+	p.mu.Unlock()
+	p.hub.Unregister(pc.conn)
+}
+
+// Run отключает подключения без опросов дольше pollIdleTTL (presence.left для остальных участников).
+func (p *Pollers) Run(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			var idle []*PollConn
+			p.mu.Lock()
+			for _, pc := range p.conns {
+				pc.mu.Lock()
+				if now.Sub(pc.lastPoll) > pollIdleTTL && len(pc.sem) == 0 {
+					idle = append(idle, pc)
+				}
+				pc.mu.Unlock()
+			}
+			p.mu.Unlock()
+			for _, pc := range idle {
+				p.remove(pc)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+)
+
+func TestPollReplaySkipsLiveDuplicates(t *testing.T) {
+	h := NewDataHub()
+	pollers := NewPollers(h)
+	sessionID, alice, bob := uuid.New(), uuid.New(), uuid.New()
+
+	pc := pollers.Join(sessionID, alice, RoleParticipant, time.Time{})
+	sender, _ := connect(t, h, sessionID, bob, RoleParticipant, nil)
+
+	// Кадр с номером 5 сохранён во время досылки: он приходит и живым, и из истории.
+	seq := int64(4)
+	persist := func(uuid.UUID, uuid.UUID, []byte) (int64, error) {
+		seq++
+		return seq, nil
+	}
+	if _, err := h.Relay(sender, []byte(`{"n":5}`), persist); err != nil {
+		t.Fatal(err)
+	}
+	pc.Replay([]model.ChannelMessage{
+		{Seq: 3, Payload: []byte(`{"n":3}`)},
+		{Seq: 4, Payload: []byte(`{"n":4}`)},
+		{Seq: 5, Payload: []byte(`{"n":5}`)},
+	})
+	if _, err := h.Relay(sender, []byte(`{"n":6}`), persist); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pollers.Poll(context.Background(), pc, 0, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, ev := range res.Events {
+		if ev.Seq > 0 {
+			got = append(got, ev.Seq)
+		}
+	}
+	want := []int64{3, 4, 5, 6}
+	if len(got) != len(want) {
+		t.Fatalf("seqs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("seqs = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm/clause"
+)
+
+// SystemSenderID — отправитель системных сообщений PublishMessage (channel_messages.user_id).
+var SystemSenderID = uuid.Nil
+
+// ErrInvalidMessage — недопустимый вид, содержимое или ключ идемпотентности публикуемого сообщения.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Ограничения PublishMessage: размер содержимого и длина ключа идемпотентности.
+const (
+	PublishMaxPayload     = 64 << 10
+	publishMaxIdempotency = 128
+)
+
+var publishKindRe = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,31}$`)
+
+// Виды, занятые кадрами клиентов и служебными событиями: публиковать их от имени сервиса нельзя.
+var (
+	publishReservedKinds    = []string{MessageKindData, EventError}
+	publishReservedPrefixes = []string{"file.", "message.", "presence.", "moderation.", "session."}
+)
+
+// PublishRequest — сообщение, публикуемое в сессию сервисом или ботом (PublishMessage).
+type PublishRequest struct {
+	SessionID uuid.UUID
+	SenderID  uuid.UUID // SystemSenderID — система
+	Kind      string
+	Payload   []byte // JSON
+	// IdempotencyKey — повтор с тем же ключом от того же отправителя возвращает первое сообщение.
+	IdempotencyKey string
+	// Trusted — вызывающий — сервис: отправитель не проверяется. Иначе отправитель пишет от своего имени
+	// и подчиняется правилам участника (членство, viewer, ban, mute).
+	Trusted bool
+}
+
+// PublishMessage сохраняет сообщение в историю (AppendMessage, kind — вид сообщения, payload — событие
+// с данными и отправителем) и рассылает его подключённым участникам. duplicate — сообщение с этим ключом
+// идемпотентности уже сохранено: возвращается оно и рассылается снова с прежним seq.
+func (s *DataService) PublishMessage(ctx context.Context, req PublishRequest) (msg *model.ChannelMessage, duplicate bool, err error) {
+	if err := validatePublish(req); err != nil {
+		return nil, false, err
+	}
+	if req.Trusted {
+		err = s.checkSessionOpen(ctx, req.SessionID)
+	} else {
+		err = s.checkPublisher(ctx, req.SessionID, req.SenderID)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	ev := Event{
+		Type:      req.Kind,
+		SessionID: req.SessionID,
+		SenderID:  &req.SenderID,
+		Data:      json.RawMessage(req.Payload),
+		Time:      time.Now().UTC(),
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if req.IdempotencyKey == "" {
+		msg, err = s.AppendMessage(req.SessionID, req.SenderID, req.Kind, payload)
+	} else {
+		msg, duplicate, err = s.appendMessageOnce(ctx, &model.ChannelMessage{
+			SessionID:      req.SessionID,
+			UserID:         req.SenderID,
+			Kind:           req.Kind,
+			Payload:        payload,
+			IdempotencyKey: &req.IdempotencyKey,
+		})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if duplicate {
+		// Первая попытка могла сохранить сообщение, но не разослать его: рассылается сохранённое событие
+		// с тем же seq, подписчики отбрасывают повтор по seq.
+		if ev, err = storedEvent(msg); err != nil {
+			return nil, false, err
+		}
+	}
+	ev.Seq = msg.Seq
+	if s.events != nil {
+		s.events.Publish(ev)
+	}
+	return msg, duplicate, nil
+}
+
+// storedEvent восстанавливает событие из payload сохранённого сообщения.
+func storedEvent(msg *model.ChannelMessage) (Event, error) {
+	var stored struct {
+		Event
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Payload, &stored); err != nil {
+		return Event{}, fmt.Errorf("stored message %d: %w", msg.Seq, err)
+	}
+	stored.Event.Data = stored.Data
+	return stored.Event, nil
+}
+
+func validatePublish(req PublishRequest) error {
+	if !publishKindRe.MatchString(req.Kind) {
+		return fmt.Errorf("%w: kind must match %s", ErrInvalidMessage, publishKindRe)
+	}
+	for _, k := range publishReservedKinds {
+		if req.Kind == k {
+			return fmt.Errorf("%w: kind %q is reserved", ErrInvalidMessage, req.Kind)
+		}
+	}
+	for _, p := range publishReservedPrefixes {
+		if strings.HasPrefix(req.Kind, p) {
+			return fmt.Errorf("%w: kind %q is reserved", ErrInvalidMessage, req.Kind)
+		}
+	}
+	if len(req.Payload) > PublishMaxPayload {
+		return fmt.Errorf("%w: payload exceeds %d bytes", ErrInvalidMessage, PublishMaxPayload)
+	}
+	if !json.Valid(req.Payload) {
+		return fmt.Errorf("%w: payload must be JSON", ErrInvalidMessage)
+	}
+	if len(req.IdempotencyKey) > publishMaxIdempotency {
+		return fmt.Errorf("%w: idempotency key exceeds %d bytes", ErrInvalidMessage, publishMaxIdempotency)
+	}
+	return nil
+}
+
+// checkPublisher применяет к отправителю правила кадра участника: членство, роль не viewer,
+// сессия открыта, нет ban и mute.
+func (s *DataService) checkPublisher(ctx context.Context, sessionID, userID uuid.UUID) error {
+	role, err := s.MemberRole(ctx, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if role == RoleViewer {
+		return ErrReadOnly
+	}
+	return s.checkRestrictions(ctx, sessionID, userID)
+}
+
+// appendMessageOnce сохраняет сообщение с ключом идемпотентности; если ключ уже занят (в том числе
+// параллельным запросом), возвращает ранее сохранённое сообщение и duplicate.
+func (s *DataService) appendMessageOnce(ctx context.Context, msg *model.ChannelMessage) (*model.ChannelMessage, bool, error) {
+	rows, err := s.insertMessage(ctx, msg, clause.OnConflict{
+		Columns:     []clause.Column{{Name: "session_id"}, {Name: "user_id"}, {Name: "idempotency_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "idempotency_key IS NOT NULL"}}},
+		DoNothing:   true,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if rows > 0 {
+		return msg, false, nil
+	}
+	var prev model.ChannelMessage
+	err = s.db.WithContext(ctx).
+		Where("session_id = ? AND user_id = ? AND idempotency_key = ?", msg.SessionID, msg.UserID, *msg.IdempotencyKey).
+		First(&prev).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &prev, true, nil
+}
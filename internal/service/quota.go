@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Области учёта квот.
+const (
+	QuotaScopeSession = "session"
+	QuotaScopeUser    = "user"
+)
+
+// ErrQuotaExceeded — загрузка превысила бы квоту сессии или пользователя.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// Quotas — лимиты на суммарный объём и число файлов; 0 — без ограничения.
+type Quotas struct {
+	SessionBytes int64
+	SessionFiles int64
+	UserBytes    int64
+	UserFiles    int64
+}
+
+// Usage — потребление в одной области учёта относительно её лимитов (0 — без ограничения).
+type Usage struct {
+	Scope      string
+	ScopeID    uuid.UUID
+	BytesUsed  int64
+	FilesCount int64
+	BytesLimit int64
+	FilesLimit int64
+}
+
+// SetQuotas задаёт квоты на сессию и пользователя.
+func (s *DataService) SetQuotas(q Quotas) { s.quotas = q }
+
+// chargeQuota учитывает новый файл в storage_usage сессии и пользователя внутри транзакции tx.
+// Строки учёта блокируются до commit, поэтому параллельные загрузки не обходят лимит.
+func (s *DataService) chargeQuota(tx *gorm.DB, sessionID, userID uuid.UUID, size int64) error {
+	if err := chargeScope(tx, QuotaScopeSession, sessionID, size, s.quotas.SessionBytes, s.quotas.SessionFiles); err != nil {
+		return err
+	}
+	return chargeScope(tx, QuotaScopeUser, userID, size, s.quotas.UserBytes, s.quotas.UserFiles)
+}
+
+func chargeScope(tx *gorm.DB, scope string, scopeID uuid.UUID, size, bytesLimit, filesLimit int64) error {
+	u := &model.StorageUsage{Scope: scope, ScopeID: scopeID, BytesUsed: size, FilesCount: 1, UpdatedAt: time.Now()}
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "scope_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"bytes_used":  gorm.Expr("storage_usage.bytes_used + ?", size),
+			"files_count": gorm.Expr("storage_usage.files_count + 1"),
+			"updated_at":  time.Now(),
+		}),
+	}, clause.Returning{}).Create(u).Error
+	if err != nil {
+		return fmt.Errorf("charge quota: %w", err)
+	}
+	return checkQuota(scope, u, size, bytesLimit, filesLimit)
+}
+
+// checkQuota сверяет потребление области scope (уже с новым файлом размера size) с её лимитами.
+func checkQuota(scope string, u *model.StorageUsage, size, bytesLimit, filesLimit int64) error {
+	if bytesLimit > 0 && u.BytesUsed > bytesLimit {
+		return fmt.Errorf("%w: %s limit %d bytes, used %d, file %d", ErrQuotaExceeded, scope, bytesLimit, u.BytesUsed-size, size)
+	}
+	if filesLimit > 0 && u.FilesCount > filesLimit {
+		return fmt.Errorf("%w: %s limit %d files", ErrQuotaExceeded, scope, filesLimit)
+	}
+	return nil
+}
+
+// refundQuota возвращает квоту удалённого файла.
+func refundQuota(tx *gorm.DB, f *model.ChannelFile) error {
+	for _, sc := range []struct {
+		scope string
+		id    uuid.UUID
+	}{{QuotaScopeSession, f.SessionID}, {QuotaScopeUser, f.UserID}} {
+		err := tx.Model(&model.StorageUsage{}).
+			Where("scope = ? AND scope_id = ?", sc.scope, sc.id).
+			Updates(map[string]interface{}{
+				"bytes_used":  gorm.Expr("GREATEST(bytes_used - ?, 0)", f.SizeBytes),
+				"files_count": gorm.Expr("GREATEST(files_count - 1, 0)"),
+				"updated_at":  time.Now(),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("refund quota: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetUsage возвращает потребление сессии и (если задан userID) пользователя относительно квот.
+func (s *DataService) GetUsage(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) ([]Usage, error) {
+	scopes := []Usage{{Scope: QuotaScopeSession, ScopeID: sessionID, BytesLimit: s.quotas.SessionBytes, FilesLimit: s.quotas.SessionFiles}}
+	if userID != nil {
+		scopes = append(scopes, Usage{Scope: QuotaScopeUser, ScopeID: *userID, BytesLimit: s.quotas.UserBytes, FilesLimit: s.quotas.UserFiles})
+	}
+	for i := range scopes {
+		var row model.StorageUsage
+		err := s.db.WithContext(ctx).Where("scope = ? AND scope_id = ?", scopes[i].Scope, scopes[i].ScopeID).First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scopes[i].BytesUsed, scopes[i].FilesCount = row.BytesUsed, row.FilesCount
+	}
+	return scopes, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+)
+
+func TestCheckQuota(t *testing.T) {
+	cases := []struct {
+		name       string
+		bytesUsed  int64
+		filesCount int64
+		size       int64
+		bytesLimit int64
+		filesLimit int64
+		wantErr    bool
+	}{
+		{name: "no limits", bytesUsed: 1 << 40, filesCount: 1 << 20, size: 1 << 30},
+		{name: "below byte limit", bytesUsed: 900, filesCount: 3, size: 100, bytesLimit: 1000},
+		{name: "exactly at byte limit", bytesUsed: 1000, filesCount: 3, size: 100, bytesLimit: 1000},
+		{name: "over byte limit", bytesUsed: 1001, filesCount: 3, size: 100, bytesLimit: 1000, wantErr: true},
+		{name: "single file over byte limit", bytesUsed: 2000, filesCount: 1, size: 2000, bytesLimit: 1000, wantErr: true},
+		{name: "exactly at file limit", bytesUsed: 10, filesCount: 5, size: 1, filesLimit: 5},
+		{name: "over file limit", bytesUsed: 10, filesCount: 6, size: 1, filesLimit: 5, wantErr: true},
+		{name: "file limit with room in bytes", bytesUsed: 10, filesCount: 6, size: 1, bytesLimit: 1 << 20, filesLimit: 5, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &model.StorageUsage{BytesUsed: tc.bytesUsed, FilesCount: tc.filesCount}
+			err := checkQuota(QuotaScopeUser, u, tc.size, tc.bytesLimit, tc.filesLimit)
+			if tc.wantErr != errors.Is(err, ErrQuotaExceeded) {
+				t.Fatalf("checkQuota error = %v, want exceeded %v", err, tc.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), QuotaScopeUser) {
+				t.Fatalf("error %q does not name the scope", err)
+			}
+		})
+	}
+}
+
+func TestChargeQuotaScopes(t *testing.T) {
+	db := dryRunDB(t)
+	// Без подключения строка учёта не перечитывается: потребление — только новый файл.
+	cases := []struct {
+		name      string
+		quotas    Quotas
+		size      int64
+		wantScope string
+	}{
+		{name: "within both", quotas: Quotas{SessionBytes: 100, UserBytes: 100}, size: 100},
+		{name: "session bytes", quotas: Quotas{SessionBytes: 50, UserBytes: 100}, size: 60, wantScope: QuotaScopeSession},
+		{name: "user bytes", quotas: Quotas{SessionBytes: 100, UserBytes: 50}, size: 60, wantScope: QuotaScopeUser},
+		{name: "unlimited", size: 1 << 40},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDataService(db, nil, DefaultFilePolicy())
+			s.SetQuotas(tc.quotas)
+			err := s.chargeQuota(db, uuid.New(), uuid.New(), tc.size)
+			if tc.wantScope == "" {
+				if err != nil {
+					t.Fatalf("chargeQuota: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrQuotaExceeded) || !strings.Contains(err.Error(), tc.wantScope+" limit") {
+				t.Fatalf("chargeQuota error = %v, want %s quota exceeded", err, tc.wantScope)
+			}
+		})
+	}
+}
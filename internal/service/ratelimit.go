@@ -0,0 +1,322 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/psds-microservice/data-channel-service/internal/metrics"
+)
+
+// DefaultKind — ключ лимита для видов кадров, не перечисленных явно.
+const DefaultKind = "*"
+
+// Области лимитов: одно подключение, пользователь (все его подключения на экземпляре), сессия целиком.
+const (
+	ScopeConn    = "conn"
+	ScopeUser    = "user"
+	ScopeSession = "session"
+)
+
+// CloseRateLimited — код закрытия подключения после повторных превышений лимитов.
+const CloseRateLimited = websocket.ClosePolicyViolation
+
+const (
+	// errorFrameInterval — не чаще одного кадра error на подключение, чтобы ответы не стали потоком.
+	errorFrameInterval = time.Second
+	bucketIdleTTL      = 5 * time.Minute
+)
+
+var (
+	framesReceived = metrics.NewCounterVec("data_channel_frames_received_total",
+		"Inbound WebSocket frames by kind.", "kind")
+	framesLimited = metrics.NewCounterVec("data_channel_frames_rate_limited_total",
+		"Inbound frames dropped by rate limits.", "scope", "kind")
+	rateLimitDisconnects = metrics.NewCounterVec("data_channel_rate_limit_disconnects_total",
+		"Connections closed after repeated rate limit violations.")
+)
+
+// RateLimit — token bucket: Rate кадров в секунду, запас Burst.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits — лимиты по виду кадра; DefaultKind применяется к остальным видам.
+type RateLimits map[string]RateLimit
+
+// ParseRateLimits разбирает "kind=rate:burst,..." (например, "*=20:40,cursor=60:120,chat=2:5");
+// "none" — без лимитов.
+func ParseRateLimits(spec string) (RateLimits, error) {
+	out := RateLimits{}
+	if strings.TrimSpace(spec) == "none" {
+		return out, nil
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kind, v, ok := strings.Cut(item, "=")
+		rate, burst, ok2 := strings.Cut(v, ":")
+		if !ok || !ok2 || kind == "" {
+			return nil, fmt.Errorf("rate limit %q: expected kind=rate:burst", item)
+		}
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("rate limit %q: invalid rate", item)
+		}
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 1 {
+			return nil, fmt.Errorf("rate limit %q: invalid burst", item)
+		}
+		out[strings.TrimSpace(kind)] = RateLimit{Rate: r, Burst: b}
+	}
+	return out, nil
+}
+
+// lookup возвращает лимит и ключ корзины: виды без своего лимита делят одну корзину DefaultKind,
+// иначе клиент обходил бы лимит, меняя "type" в каждом кадре.
+func (l RateLimits) lookup(kind string) (RateLimit, string, bool) {
+	if lim, ok := l[kind]; ok {
+		return lim, kind, true
+	}
+	lim, ok := l[DefaultKind]
+	return lim, DefaultKind, ok
+}
+
+// RateLimitConfig — лимиты входящих кадров и реакция на повторные нарушения:
+// после MaxViolations нарушений за ViolationWindow подключение закрывается (0 — не закрывать).
+type RateLimitConfig struct {
+	Conn            RateLimits
+	User            RateLimits
+	Session         RateLimits
+	MaxViolations   int
+	ViolationWindow time.Duration
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) allow(lim RateLimit, now time.Time) bool {
+	if b.last.IsZero() {
+		b.tokens = float64(lim.Burst)
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * lim.Rate
+		if b.tokens > float64(lim.Burst) {
+			b.tokens = float64(lim.Burst)
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type bucketKey struct {
+	id   uuid.UUID
+	kind string
+}
+
+// RateLimiter хранит общие для подключений корзины пользователей и сессий (в пределах экземпляра).
+type RateLimiter struct {
+	cfg RateLimitConfig
+
+	mu       sync.Mutex
+	users    map[bucketKey]*tokenBucket
+	sessions map[bucketKey]*tokenBucket
+	senders  map[senderKey]*connLimits
+	swept    time.Time
+}
+
+// NewRateLimiter создаёт ограничитель входящих кадров.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:      cfg,
+		users:    map[bucketKey]*tokenBucket{},
+		sessions: map[bucketKey]*tokenBucket{},
+		senders:  map[senderKey]*connLimits{},
+	}
+}
+
+// SetRateLimiter включает лимиты входящих кадров (Relay).
+func (h *DataHub) SetRateLimiter(l *RateLimiter) { h.limiter = l }
+
+// connLimits — состояние лимитов одного подключения (или отправителя без подключения, см. DataHub.Sender).
+type connLimits struct {
+	mu         sync.Mutex
+	buckets    map[string]*tokenBucket
+	violations []time.Time
+	lastError  time.Time
+	last       time.Time
+	closed     atomic.Bool // подключение закрыто за нарушения; ReadPump больше не читает кадры
+}
+
+// errorDue сообщает, можно ли отправить клиенту очередной error rate_limited (не чаще errorFrameInterval).
+func (cl *connLimits) errorDue(now time.Time) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if now.Sub(cl.lastError) < errorFrameInterval {
+		return false
+	}
+	cl.lastError = now
+	return true
+}
+
+// detached — лимиты подключения для кадров пользователя, отправленных без подключения к этому экземпляру.
+func (l *RateLimiter) detached(sessionID, userID uuid.UUID) *connLimits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	k := senderKey{sessionID, userID}
+	cl := l.senders[k]
+	if cl == nil {
+		cl = &connLimits{}
+		l.senders[k] = cl
+	}
+	return cl
+}
+
+type senderKey struct {
+	sessionID uuid.UUID
+	userID    uuid.UUID
+}
+
+// metricKind — вид кадра для меток метрик: только виды из конфигурации, иначе DefaultKind.
+func (l *RateLimiter) metricKind(kind string) string {
+	for _, set := range []RateLimits{l.cfg.Conn, l.cfg.User, l.cfg.Session} {
+		if _, ok := set[kind]; ok {
+			return kind
+		}
+	}
+	return DefaultKind
+}
+
+// check списывает кадр из корзин подключения, пользователя и сессии; возвращает область,
+// лимит которой превышен ("" — кадр разрешён). Корзины областей, которые уже разрешили кадр,
+// не возвращаются: превышение тоже расходует запас, что и нужно при флуде.
+func (l *RateLimiter) check(c *DataConn, kind string, now time.Time) string {
+	if lim, key, ok := l.cfg.Conn.lookup(kind); ok {
+		cl := c.limits
+		cl.mu.Lock()
+		cl.last = now
+		if cl.buckets == nil {
+			cl.buckets = map[string]*tokenBucket{}
+		}
+		b := cl.buckets[key]
+		if b == nil {
+			b = &tokenBucket{}
+			cl.buckets[key] = b
+		}
+		allowed := b.allow(lim, now)
+		cl.mu.Unlock()
+		if !allowed {
+			return ScopeConn
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	if lim, key, ok := l.cfg.User.lookup(kind); ok && !shared(l.users, bucketKey{c.UserID, key}).allow(lim, now) {
+		return ScopeUser
+	}
+	if lim, key, ok := l.cfg.Session.lookup(kind); ok && !shared(l.sessions, bucketKey{c.SessionID, key}).allow(lim, now) {
+		return ScopeSession
+	}
+	return ""
+}
+
+func shared(m map[bucketKey]*tokenBucket, k bucketKey) *tokenBucket {
+	b := m[k]
+	if b == nil {
+		b = &tokenBucket{}
+		m[k] = b
+	}
+	return b
+}
+
+// sweep раз в bucketIdleTTL удаляет давно не использованные корзины (они уже полны).
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < bucketIdleTTL {
+		return
+	}
+	l.swept = now
+	for _, m := range []map[bucketKey]*tokenBucket{l.users, l.sessions} {
+		for k, b := range m {
+			if now.Sub(b.last) > bucketIdleTTL {
+				delete(m, k)
+			}
+		}
+	}
+	for k, cl := range l.senders {
+		cl.mu.Lock()
+		idle := now.Sub(cl.last) > bucketIdleTTL
+		cl.mu.Unlock()
+		if idle {
+			delete(l.senders, k)
+		}
+	}
+}
+
+// violation учитывает нарушение и сообщает, пора ли закрыть подключение.
+func (l *RateLimiter) violation(c *DataConn, now time.Time) bool {
+	if l.cfg.MaxViolations <= 0 {
+		return false
+	}
+	cl := c.limits
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cutoff := now.Add(-l.cfg.ViolationWindow)
+	kept := cl.violations[:0]
+	for _, t := range cl.violations {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	cl.violations = append(kept, now)
+	return len(cl.violations) >= l.cfg.MaxViolations
+}
+
+// FrameKind — вид кадра для лимитов: поле "type" JSON-объекта, иначе MessageKindData.
+func FrameKind(payload []byte) string {
+	var frame struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(payload, &frame) == nil && frame.Type != "" && len(frame.Type) <= 64 {
+		return frame.Type
+	}
+	return MessageKindData
+}
+
+// admit применяет лимиты к входящему кадру: nil — кадр можно рассылать, иначе ErrRateLimited
+// с областью и видом кадра. Повторные превышения закрывают подключение (отправителю без подключения
+// остаются отказы).
+func (h *DataHub) admit(c *DataConn, payload []byte) error {
+	l := h.limiter
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+	kind := FrameKind(payload)
+	mkind := l.metricKind(kind)
+	framesReceived.Inc(mkind)
+	scope := l.check(c, kind, now)
+	if scope == "" {
+		return nil
+	}
+	framesLimited.Inc(scope, mkind)
+	if l.violation(c, now) && (c.transport != nil || c.done != nil) && !c.limits.closed.Swap(true) {
+		rateLimitDisconnects.Inc()
+		c.closeWith(CloseRateLimited, "rate limit exceeded")
+	}
+	return fmt.Errorf("%w: %s limit for %q frames", ErrRateLimited, scope, kind)
+}
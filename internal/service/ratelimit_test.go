@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    string
+		want    RateLimits
+		wantErr string
+	}{
+		{name: "several kinds", spec: " *=20:40, cursor=60:120 ,chat=0.5:5", want: RateLimits{
+			"*": {Rate: 20, Burst: 40}, "cursor": {Rate: 60, Burst: 120}, "chat": {Rate: 0.5, Burst: 5},
+		}},
+		{name: "none", spec: "none", want: RateLimits{}},
+		{name: "empty", spec: "", want: RateLimits{}},
+		{name: "no burst", spec: "*=20", wantErr: "expected kind=rate:burst"},
+		{name: "no kind", spec: "=20:40", wantErr: "expected kind=rate:burst"},
+		{name: "zero rate", spec: "*=0:40", wantErr: "invalid rate"},
+		{name: "negative rate", spec: "*=-1:40", wantErr: "invalid rate"},
+		{name: "zero burst", spec: "*=20:0", wantErr: "invalid burst"},
+		{name: "fractional burst", spec: "*=20:1.5", wantErr: "invalid burst"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseRateLimits(tc.spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseRateLimits error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("limits = %v, want %v", got, tc.want)
+			}
+			for k, v := range tc.want {
+				if got[k] != v {
+					t.Fatalf("limits[%q] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	lim := RateLimit{Rate: 2, Burst: 3}
+	start := time.Unix(1_700_000_000, 0)
+	steps := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true}, {0, true}, {0, true}, // запас Burst
+		{0, false},
+		{250 * time.Millisecond, false}, // полтокена
+		{500 * time.Millisecond, true},  // пополнение Rate в секунду
+		{500 * time.Millisecond, false},
+		{time.Hour, true}, {time.Hour, true}, {time.Hour, true}, // запас не больше Burst
+		{time.Hour, false},
+	}
+	var b tokenBucket
+	for i, s := range steps {
+		if got := b.allow(lim, start.Add(s.at)); got != s.want {
+			t.Fatalf("step %d (+%v): allow = %v, want %v", i, s.at, got, s.want)
+		}
+	}
+}
+
+func TestRateLimiterScopes(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	// frame — кадр от подключения conns[conn]: 0 — alice и 1 — bob в одной сессии, 2 — alice в другой.
+	type frame struct {
+		conn int
+		kind string
+	}
+	cases := []struct {
+		name   string
+		cfg    RateLimitConfig
+		frames []frame
+		want   []string // область отказа для каждого кадра ("" — разрешён)
+	}{
+		{
+			name:   "conn limit per connection",
+			cfg:    RateLimitConfig{Conn: RateLimits{DefaultKind: {Rate: 1, Burst: 1}}},
+			frames: []frame{{0, "chat"}, {0, "chat"}, {1, "chat"}},
+			want:   []string{"", ScopeConn, ""},
+		},
+		{
+			name:   "unlisted kinds share the default bucket",
+			cfg:    RateLimitConfig{Conn: RateLimits{DefaultKind: {Rate: 1, Burst: 2}, "cursor": {Rate: 1, Burst: 1}}},
+			frames: []frame{{0, "a"}, {0, "b"}, {0, "c"}, {0, "cursor"}, {0, "cursor"}},
+			want:   []string{"", "", ScopeConn, "", ScopeConn},
+		},
+		{
+			name:   "user limit across connections",
+			cfg:    RateLimitConfig{User: RateLimits{DefaultKind: {Rate: 1, Burst: 2}}},
+			frames: []frame{{0, "chat"}, {2, "chat"}, {0, "chat"}, {1, "chat"}},
+			want:   []string{"", "", ScopeUser, ""},
+		},
+		{
+			name:   "session limit across users",
+			cfg:    RateLimitConfig{Session: RateLimits{"chat": {Rate: 1, Burst: 2}}},
+			frames: []frame{{0, "chat"}, {1, "chat"}, {0, "chat"}, {2, "chat"}, {0, "cursor"}},
+			want:   []string{"", "", ScopeSession, "", ""},
+		},
+		{
+			name:   "no limits",
+			cfg:    RateLimitConfig{},
+			frames: []frame{{0, "chat"}, {0, "chat"}, {0, "chat"}},
+			want:   []string{"", "", ""},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewDataHub()
+			l := NewRateLimiter(tc.cfg)
+			h.SetRateLimiter(l)
+			sessionID, alice, bob := uuid.New(), uuid.New(), uuid.New()
+			conns := []*DataConn{
+				h.Register(sessionID, alice, RoleParticipant, nil),
+				h.Register(sessionID, bob, RoleParticipant, nil),
+				h.Register(uuid.New(), alice, RoleParticipant, nil),
+			}
+			for i, f := range tc.frames {
+				if got := l.check(conns[f.conn], f.kind, now); got != tc.want[i] {
+					t.Fatalf("frame %d (%s from %d): scope = %q, want %q", i, f.kind, f.conn, got, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitViolationsCloseConnection(t *testing.T) {
+	h := NewDataHub()
+	h.SetRateLimiter(NewRateLimiter(RateLimitConfig{
+		Conn:            RateLimits{DefaultKind: {Rate: 0.001, Burst: 1}},
+		MaxViolations:   3,
+		ViolationWindow: time.Minute,
+	}))
+	c, tr := connect(t, h, uuid.New(), uuid.New(), RoleParticipant, nil)
+	next(t, tr) // presence.state
+
+	if err := h.admit(c, []byte(`{"type":"chat"}`)); err != nil {
+		t.Fatalf("first frame: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		err := h.admit(c, []byte(`{"type":"chat"}`))
+		if !errors.Is(err, ErrRateLimited) || !strings.Contains(err.Error(), `conn limit for "chat"`) {
+			t.Fatalf("frame %d error = %v, want conn rate limit", i+2, err)
+		}
+	}
+	if info := waitClosed(t, tr); info.Code != CloseRateLimited {
+		t.Fatalf("close code = %d, want %d", info.Code, CloseRateLimited)
+	}
+}
+
+func TestFrameKind(t *testing.T) {
+	cases := map[string]string{
+		`{"type":"cursor","x":1}`: "cursor",
+		`{"x":1}`:                 MessageKindData,
+		`{"type":""}`:             MessageKindData,
+		`{"type":7}`:              MessageKindData,
+		`not json`:                MessageKindData,
+		`{"type":"` + strings.Repeat("a", 65) + `"}`: MessageKindData,
+	}
+	for payload, want := range cases {
+		if got := FrameKind([]byte(payload)); got != want {
+			t.Errorf("FrameKind(%.40q) = %q, want %q", payload, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/psds-microservice/data-channel-service/internal/metastrip"
+)
+
+// SanitizePolicy — удаление метаданных (EXIF/XMP/IPTC) из загружаемых изображений.
+// KeepOriginal сохраняет исходное содержимое рядом с очищенным (channel_files.original_sha256);
+// участникам сессии всегда выдаётся очищенная копия.
+type SanitizePolicy struct {
+	Enabled      bool
+	KeepOriginal bool
+}
+
+// SetSanitizePolicy включает этап очистки метаданных в конвейере загрузки.
+func (s *DataService) SetSanitizePolicy(p SanitizePolicy) { s.sanitize = p }
+
+// sanitizeSpool пишет копию src без метаданных во временный файл. Если метаданных не было,
+// возвращает nil: сохраняется исходное содержимое. Повреждённое изображение — ErrInvalidFile,
+// чтобы файл с неудалёнными метаданными не попал в хранилище.
+func sanitizeSpool(src *os.File, contentType string) (*os.File, string, int64, error) {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, "", 0, err
+	}
+	tmp, err := os.CreateTemp("", "sanitized-*")
+	if err != nil {
+		return nil, "", 0, err
+	}
+	h := sha256.New()
+	cw := &countWriter{w: io.MultiWriter(tmp, h)}
+	removed, err := metastrip.Strip(contentType, src, cw)
+	if err == nil && removed > 0 {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil || removed == 0 {
+		discardSpool(tmp)
+		if errors.Is(err, metastrip.ErrFormat) {
+			return nil, "", 0, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		return nil, "", 0, err
+	}
+	return tmp, hex.EncodeToString(h.Sum(nil)), cw.n, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
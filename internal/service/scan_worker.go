@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/scan"
+)
+
+// Статусы антивирусной проверки в channel_files.scan_status. Скачивание разрешено только для clean.
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+	ScanError    = "error"
+)
+
+// ErrFileNotScanned — проверка файла ещё не завершена (или завершилась ошибкой);
+// ErrFileQuarantined — файл заражён и помещён в карантин.
+var (
+	ErrFileNotScanned  = errors.New("file has not passed malware scan yet")
+	ErrFileQuarantined = errors.New("file is quarantined")
+)
+
+// scanTimeout ограничивает проверку одного файла. Файлы в pending и error дозапускаются
+// каждые scanRequeueInterval; после ошибки повтор откладывается от scanRetryBase, удваиваясь до scanRetryMax.
+// После scanMaxAttempts неудач подряд статус error окончательный: файл больше не проверяется.
+const (
+	scanTimeout         = 2 * time.Minute
+	scanRequeueInterval = time.Minute
+	scanRetryBase       = time.Minute
+	scanRetryMax        = time.Hour
+	scanMaxAttempts     = 8
+)
+
+// ScanWorker проверяет загруженные файлы сканером в фоне и обновляет scan_status.
+// Чистые файлы передаются генератору превью, о заражённых сообщается в сессию.
+type ScanWorker struct {
+	data    *DataService
+	scanner scan.Scanner
+	workers int
+	queue   chan uuid.UUID
+
+	mu     sync.Mutex
+	queued map[uuid.UUID]struct{} // в очереди или проверяются сейчас
+}
+
+// NewScanWorker создаёт фоновую проверку файлов сервиса data.
+func NewScanWorker(data *DataService, scanner scan.Scanner, workers int) *ScanWorker {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &ScanWorker{
+		data: data, scanner: scanner, workers: workers,
+		queue: make(chan uuid.UUID, 1024), queued: make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Enqueue ставит файл в очередь без блокировки; при переполнении файл остаётся pending
+// и будет поставлен снова периодическим дозапуском.
+func (w *ScanWorker) Enqueue(fileID uuid.UUID) {
+	if !w.tryEnqueue(fileID) {
+		log.Printf("scan: queue full, %s deferred", fileID)
+	}
+}
+
+// tryEnqueue ставит файл в очередь, если его там ещё нет; false — очередь заполнена.
+func (w *ScanWorker) tryEnqueue(fileID uuid.UUID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.queued[fileID]; ok {
+		return true
+	}
+	select {
+	case w.queue <- fileID:
+		w.queued[fileID] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *ScanWorker) finished(fileID uuid.UUID) {
+	w.mu.Lock()
+	delete(w.queued, fileID)
+	w.mu.Unlock()
+}
+
+// Run запускает воркеры, периодически дозапускает файлы в pending/error и блокируется до отмены ctx.
+func (w *ScanWorker) Run(ctx context.Context) {
+	go w.requeue(ctx)
+	done := make(chan struct{})
+	for i := 0; i < w.workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-w.queue:
+					if err := w.process(ctx, id); err != nil {
+						log.Printf("scan %s: %v", id, err)
+					}
+					w.finished(id)
+				}
+			}
+		}()
+	}
+	for i := 0; i < w.workers; i++ {
+		<-done
+	}
+}
+
+// requeue при старте и затем каждые scanRequeueInterval ставит в очередь файлы в pending
+// (не поместившиеся в очередь или оставшиеся после перезапуска) и в error, чья задержка повтора истекла.
+func (w *ScanWorker) requeue(ctx context.Context) {
+	t := time.NewTicker(scanRequeueInterval)
+	defer t.Stop()
+	for {
+		w.requeueDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (w *ScanWorker) requeueDue(ctx context.Context) {
+	free := cap(w.queue) - len(w.queue)
+	if free <= 0 {
+		return
+	}
+	var ids []uuid.UUID
+	if err := w.data.db.WithContext(ctx).Model(&model.ChannelFile{}).
+		Where("scan_status = ? OR (scan_status = ? AND scan_attempts < ? AND (scan_retry_at IS NULL OR scan_retry_at <= ?))",
+			ScanPending, ScanError, scanMaxAttempts, time.Now()).
+		Order("created_at").Limit(free).Pluck("id", &ids).Error; err != nil {
+		if ctx.Err() == nil {
+			log.Printf("scan: requeue: %v", err)
+		}
+		return
+	}
+	for _, id := range ids {
+		if !w.tryEnqueue(id) {
+			return
+		}
+	}
+}
+
+// scanRetryDelay — задержка перед повтором после attempts неудачных проверок подряд.
+func scanRetryDelay(attempts int) time.Duration {
+	d := scanRetryBase
+	for i := 1; i < attempts && d < scanRetryMax; i++ {
+		d *= 2
+	}
+	return min(d, scanRetryMax)
+}
+
+func (w *ScanWorker) process(ctx context.Context, fileID uuid.UUID) error {
+	f, err := w.data.GetFile(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if f.ScanStatus != ScanPending && (f.ScanStatus != ScanError || f.ScanAttempts >= scanMaxAttempts) {
+		return nil
+	}
+	result, scanErr := w.scan(ctx, f)
+	now := time.Now()
+	updates := map[string]interface{}{"scanned_at": now, "scan_attempts": 0, "scan_retry_at": nil}
+	switch {
+	case scanErr != nil:
+		attempts := f.ScanAttempts + 1
+		updates["scan_status"] = ScanError
+		updates["scan_attempts"] = attempts
+		if attempts < scanMaxAttempts {
+			updates["scan_retry_at"] = now.Add(scanRetryDelay(attempts))
+		} else {
+			log.Printf("scan %s: giving up after %d attempts", f.ID, attempts)
+		}
+	case result.Infected:
+		updates["scan_status"] = ScanInfected
+		updates["scan_signature"] = result.Signature
+	default:
+		updates["scan_status"] = ScanClean
+	}
+	if err := w.data.db.WithContext(ctx).Model(&model.ChannelFile{}).Where("id = ?", f.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	// Событие — только при смене статуса: повторные неудачи проверки в сессию не рассылаются.
+	changed := f.ScanStatus != updates["scan_status"]
+	f.ScanStatus = updates["scan_status"].(string)
+	data := w.data.fileEventData(f)
+	data.Signature = result.Signature
+	switch {
+	case scanErr != nil:
+		if changed {
+			w.data.emit(ctx, f.SessionID, f.UserID, EventFileScanned, data)
+		}
+		return scanErr
+	case result.Infected:
+		log.Printf("scan %s: quarantined (%s)", f.ID, result.Signature)
+		w.data.emit(ctx, f.SessionID, f.UserID, EventFileQuarantined, data)
+	default:
+		w.data.emit(ctx, f.SessionID, f.UserID, EventFileScanned, data)
+		if f.PreviewStatus == PreviewPending && w.data.previews != nil {
+			w.data.previews.Enqueue(f.ID)
+		}
+	}
+	return nil
+}
+
+func (w *ScanWorker) scan(ctx context.Context, f *model.ChannelFile) (scan.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+	rc, err := w.data.store.Open(ctx, f.StoragePath)
+	if err != nil {
+		return scan.Result{}, err
+	}
+	defer rc.Close()
+	return w.scanner.Scan(ctx, rc)
+}
+
+// checkScan разрешает выдачу содержимого только проверенным чистым файлам.
+func checkScan(f *model.ChannelFile) error {
+	switch f.ScanStatus {
+	case ScanClean:
+		return nil
+	case ScanInfected:
+		return ErrFileQuarantined
+	default:
+		return ErrFileNotScanned
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Статусы сессии (model.Session.Status); сессия без строки в sessions считается открытой.
+const (
+	SessionOpen   = "open"
+	SessionClosed = "closed"
+)
+
+// CloseSessionClosed — код закрытия подключений закрытой сессии.
+const CloseSessionClosed = 4004
+
+const (
+	maxSessionTitle    = 255
+	maxSessionMetadata = 16 << 10
+
+	// sessionStateTTL — сколько кэшируется статус открытой сессии при записи кадров (закрытая
+	// сессия не открывается снова, её статус кэшируется до вытеснения); sessionStateCacheMax — размер кэша.
+	sessionStateTTL      = 5 * time.Second
+	sessionStateCacheMax = 10000
+	// closedSessionTTL — сколько DataHub помнит сессию, закрытую на этом экземпляре: этого хватает,
+	// чтобы отклонить подключения, проверенные до закрытия; позже их отклоняет проверка в БД.
+	closedSessionTTL = 10 * time.Minute
+)
+
+var (
+	// ErrSessionNotFound — сессии нет в таблице sessions.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionExists — сессия с этим идентификатором уже создана.
+	ErrSessionExists = errors.New("session already exists")
+	// ErrSessionClosed — сессия закрыта: подключения, сообщения и загрузки отклоняются, история доступна.
+	ErrSessionClosed = errors.New("session is closed")
+	// ErrInvalidSession — недопустимые название или метаданные сессии.
+	ErrInvalidSession = errors.New("invalid session")
+)
+
+// SessionInput — данные новой сессии. ID uuid.Nil — идентификатор назначается сервисом;
+// Metadata — JSON-объект (nil — пустой).
+type SessionInput struct {
+	ID        uuid.UUID
+	Title     string
+	Metadata  []byte
+	CreatedBy uuid.UUID
+}
+
+// SessionClosedData — данные события session.closed.
+type SessionClosedData struct {
+	ClosedBy uuid.UUID `json:"closed_by"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// CreateSession создаёт открытую сессию. При локальном составе (session_members) создатель
+// становится владельцем, если в сессии ещё нет участников.
+func (s *DataService) CreateSession(ctx context.Context, in SessionInput) (*model.Session, error) {
+	if len(in.Title) > maxSessionTitle {
+		return nil, fmt.Errorf("%w: title exceeds %d bytes", ErrInvalidSession, maxSessionTitle)
+	}
+	if in.Metadata == nil {
+		in.Metadata = []byte("{}")
+	}
+	var obj map[string]interface{}
+	if len(in.Metadata) > maxSessionMetadata || json.Unmarshal(in.Metadata, &obj) != nil || obj == nil {
+		return nil, fmt.Errorf("%w: metadata must be a JSON object up to %d bytes", ErrInvalidSession, maxSessionMetadata)
+	}
+	if in.ID == uuid.Nil {
+		in.ID = uuid.New()
+	}
+	sess := &model.Session{
+		ID:        in.ID,
+		Title:     in.Title,
+		Metadata:  in.Metadata,
+		Status:    SessionOpen,
+		CreatedBy: &in.CreatedBy,
+	}
+	_, local := s.members.(*LocalMembers)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(sess)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSessionExists
+		}
+		if !local {
+			return nil
+		}
+		var n int64
+		if err := tx.Model(&model.SessionMember{}).Where("session_id = ?", sess.ID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+		return tx.Create(&model.SessionMember{SessionID: sess.ID, UserID: in.CreatedBy, Role: RoleOwner, AddedBy: &in.CreatedBy}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// GetSession возвращает сессию; ErrSessionNotFound — сессия не создавалась через CreateSession и не закрывалась.
+func (s *DataService) GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error) {
+	var sess model.Session
+	if err := s.db.WithContext(ctx).First(&sess, "id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// CloseSession закрывает сессию (модератор; без источника состава — создатель): подключения на всех экземплярах получают событие
+// session.closed и закрываются с кодом CloseSessionClosed; новые подключения, сообщения и загрузки
+// отклоняются (ErrSessionClosed). Сессию без строки (созданную неявно) закрыть тоже можно;
+// повторное закрытие возвращает сессию без изменений.
+func (s *DataService) CloseSession(ctx context.Context, sessionID, actorID uuid.UUID, reason string) (*model.Session, error) {
+	allowed, err := s.canCloseSession(ctx, sessionID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: only session moderators can close the session", ErrForbidden)
+	}
+	if len(reason) > maxModerationReason {
+		reason = reason[:maxModerationReason]
+	}
+	now := time.Now().UTC()
+	sess := &model.Session{
+		ID:          sessionID,
+		Metadata:    []byte("{}"),
+		Status:      SessionClosed,
+		ClosedBy:    &actorID,
+		CloseReason: reason,
+		ClosedAt:    &now,
+	}
+	res := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "closed_by", "close_reason", "closed_at", "updated_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "sessions.status <> ?", Vars: []interface{}{SessionClosed}}}},
+	}).Create(sess)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return s.GetSession(ctx, sessionID)
+	}
+	s.states.put(sessionID, sessionState{closed: true})
+	closed, err := s.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	notice := ModerationNotice{
+		ActionID:  uuid.New(),
+		Action:    ModerationCloseSession,
+		SessionID: sessionID,
+		ActorID:   actorID,
+		Reason:    reason,
+	}
+	// Сессия уже закрыта в базе: при сбое доставки подключения будут отклонены при следующем входе.
+	if err := s.publishModeration(ctx, notice); err != nil {
+		log.Printf("session %s: deliver close: %v", sessionID, err)
+	}
+	return closed, nil
+}
+
+// canCloseSession: закрывает модератор сессии. Без источника состава и модераторов ролей нет —
+// владельцем считается создатель (sessions.created_by).
+func (s *DataService) canCloseSession(ctx context.Context, sessionID, actorID uuid.UUID) (bool, error) {
+	if s.members != nil || s.mods != nil {
+		return s.isModerator(ctx, sessionID, actorID)
+	}
+	sess, err := s.GetSession(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sess.CreatedBy != nil && *sess.CreatedBy == actorID, nil
+}
+
+// sessionStates — ограниченный кэш статуса сессий для записи кадров (sessionClosed).
+type sessionStates struct {
+	mu    sync.Mutex
+	cache map[uuid.UUID]sessionState
+}
+
+type sessionState struct {
+	closed  bool
+	expires time.Time // для открытой сессии
+}
+
+func (c *sessionStates) get(sessionID uuid.UUID, now time.Time) (closed, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.cache[sessionID]
+	if !ok || (!st.closed && !now.Before(st.expires)) {
+		return false, false
+	}
+	return st.closed, true
+}
+
+func (c *sessionStates) put(sessionID uuid.UUID, st sessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == nil || len(c.cache) >= sessionStateCacheMax {
+		c.cache = make(map[uuid.UUID]sessionState)
+	}
+	c.cache[sessionID] = st
+}
+
+// sessionClosed сообщает, закрыта ли сессия, по sessions.status с кэшем на sessionStateTTL:
+// экземпляр, пропустивший уведомление о закрытии или перезапущенный, перестаёт сохранять кадры.
+func (s *DataService) sessionClosed(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	now := time.Now()
+	if closed, ok := s.states.get(sessionID, now); ok {
+		return closed, nil
+	}
+	err := s.checkSessionOpen(ctx, sessionID)
+	if err != nil && !errors.Is(err, ErrSessionClosed) {
+		return false, err
+	}
+	closed := err != nil
+	s.states.put(sessionID, sessionState{closed: closed, expires: now.Add(sessionStateTTL)})
+	return closed, nil
+}
+
+// checkSessionOpen — ErrSessionClosed, если сессия закрыта.
+func (s *DataService) checkSessionOpen(ctx context.Context, sessionID uuid.UUID) error {
+	var n int64
+	err := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND status = ?", sessionID, SessionClosed).Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrSessionClosed
+	}
+	return nil
+}
+
+// closeSession отмечает сессию закрытой, рассылает участникам session.closed и закрывает их подключения
+// после доставки уже поставленных в очередь кадров.
+func (h *DataHub) closeSession(n ModerationNotice) {
+	h.markClosed(n.SessionID)
+	h.Publish(Event{Type: EventSessionClosed, SessionID: n.SessionID, Data: SessionClosedData{ClosedBy: n.ActorID, Reason: n.Reason}})
+	h.mu.RLock()
+	conns := make([]*DataConn, 0, len(h.sessions[n.SessionID]))
+	for _, c := range h.sessions[n.SessionID] {
+		conns = append(conns, c)
+	}
+	h.mu.RUnlock()
+	for _, c := range conns {
+		c.closeAfterQueued(CloseSessionClosed, "session closed")
+	}
+}
+
+// markClosed запоминает закрытие сессии на closedSessionTTL и вытесняет устаревшие отметки.
+func (h *DataHub) markClosed(sessionID uuid.UUID) {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, at := range h.closed {
+		if now.Sub(at) > closedSessionTTL {
+			delete(h.closed, id)
+		}
+	}
+	h.closed[sessionID] = now
+}
+
+// isClosed сообщает, закрыта ли сессия на этом экземпляре (closeSession, markClosed).
+func (h *DataHub) isClosed(sessionID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	at, ok := h.closed[sessionID]
+	return ok && time.Since(at) <= closedSessionTTL
+}
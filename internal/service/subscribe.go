@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrSubscriptionLagging — подписчик не успевал читать кадры, подписка закрыта; продолжать — с последнего seq.
+var ErrSubscriptionLagging = errors.New("subscriber is lagging behind: resume from the last seq")
+
+// subscriptionBuffer — очередь кадров подписчика; переполненная подписка закрывается (ErrSubscriptionLagging).
+const subscriptionBuffer = 1024
+
+// Subscription — пассивное наблюдение за сессией (или всеми сессиями) для сервисов: подписчик не входит
+// в presence и получает только рассылки всей сессии — адресованные одному клиенту кадры (presence.state,
+// error) ему не доставляются.
+type Subscription struct {
+	SessionID uuid.UUID // uuid.Nil — все сессии
+	kinds     map[string]bool
+	send      chan SessionFrame
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// SessionFrame — кадр подписки с сессией, в которую он разослан.
+type SessionFrame struct {
+	SessionID uuid.UUID
+	Frame
+}
+
+// Frames — очередь кадров подписки.
+func (s *Subscription) Frames() <-chan SessionFrame { return s.send }
+
+// Done закрывается, когда подписчик не успевает читать кадры: пропущенное читается из истории.
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Matches сообщает, относится ли к подписке вид kind (пустой список видов — любые).
+func (s *Subscription) Matches(kind string) bool {
+	return len(s.kinds) == 0 || s.kinds[kind]
+}
+
+// deliver ставит кадр в очередь; при переполнении подписка закрывается и больше кадров не принимает.
+func (s *Subscription) deliver(f SessionFrame) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	select {
+	case s.send <- f:
+	default:
+		s.closeOnce.Do(func() { close(s.done) })
+	}
+}
+
+// Subscribe подписывает на кадры сессии sessionID (uuid.Nil — всех сессий) видов kinds (пусто — всех).
+func (h *DataHub) Subscribe(sessionID uuid.UUID, kinds []string) *Subscription {
+	s := &Subscription{
+		SessionID: sessionID,
+		send:      make(chan SessionFrame, subscriptionBuffer),
+		done:      make(chan struct{}),
+	}
+	if len(kinds) > 0 {
+		s.kinds = make(map[string]bool, len(kinds))
+		for _, k := range kinds {
+			s.kinds[k] = true
+		}
+	}
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[*Subscription]struct{})
+	}
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unsubscribe снимает подписку.
+func (h *DataHub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+}
+
+// subscribersLocked — подписки на кадр вида kind сессии sessionID; вызывается под h.mu.
+func (h *DataHub) subscribersLocked(sessionID uuid.UUID, kind string) []*Subscription {
+	var out []*Subscription
+	for s := range h.subs {
+		if (s.SessionID == uuid.Nil || s.SessionID == sessionID) && s.Matches(kind) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+// Статусы превью в channel_files.preview_status.
+const (
+	PreviewNone    = "none"
+	PreviewPending = "pending"
+	PreviewReady   = "ready"
+	PreviewFailed  = "failed"
+)
+
+// maxPreviewPixels — защита от decompression bomb: изображения больше 50 Мп не декодируются.
+const maxPreviewPixels = 50_000_000
+
+// ErrPreviewNotReady — превью ещё строится; ErrPreviewUnavailable — превью для файла нет.
+var (
+	ErrPreviewNotReady    = errors.New("preview not ready")
+	ErrPreviewUnavailable = errors.New("preview not available")
+)
+
+// Thumbnailer фоново строит уменьшенные копии загруженных изображений (JPEG/PNG/GIF/WebP)
+// и кладёт их в хранилище рядом с оригиналом: thumbs/<sha256>/<size>.<ext>.
+type Thumbnailer struct {
+	db      *gorm.DB
+	store   storage.Storage
+	sizes   []int
+	workers int
+	queue   chan uuid.UUID
+}
+
+// NewThumbnailer создаёт генератор превью; sizes — длина большей стороны в пикселях.
+func NewThumbnailer(db *gorm.DB, store storage.Storage, sizes []int, workers int) *Thumbnailer {
+	sorted := append([]int(nil), sizes...)
+	sort.Ints(sorted)
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Thumbnailer{db: db, store: store, sizes: sorted, workers: workers, queue: make(chan uuid.UUID, 1024)}
+}
+
+// Supports сообщает, строится ли превью для MIME-типа.
+func (t *Thumbnailer) Supports(contentType string) bool {
+	switch mediaType(contentType) {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return len(t.sizes) > 0
+	}
+	return false
+}
+
+// Enqueue ставит файл в очередь без блокировки. При переполнении файл остаётся в статусе pending
+// и будет обработан при следующем запуске Run.
+func (t *Thumbnailer) Enqueue(fileID uuid.UUID) {
+	select {
+	case t.queue <- fileID:
+	default:
+		log.Printf("thumbnail: queue full, %s deferred", fileID)
+	}
+}
+
+// Run запускает воркеры, дозапускает файлы, оставшиеся в pending, и блокируется до отмены ctx.
+func (t *Thumbnailer) Run(ctx context.Context) {
+	go t.requeuePending(ctx)
+	done := make(chan struct{})
+	for i := 0; i < t.workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-t.queue:
+					if err := t.process(ctx, id); err != nil {
+						log.Printf("thumbnail %s: %v", id, err)
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < t.workers; i++ {
+		<-done
+	}
+}
+
+func (t *Thumbnailer) requeuePending(ctx context.Context) {
+	var ids []uuid.UUID
+	if err := t.db.WithContext(ctx).Model(&model.ChannelFile{}).
+		Where("preview_status = ? AND scan_status = ?", PreviewPending, ScanClean).Order("created_at").Limit(cap(t.queue)).
+		Pluck("id", &ids).Error; err != nil {
+		log.Printf("thumbnail: requeue pending: %v", err)
+		return
+	}
+	for _, id := range ids {
+		select {
+		case t.queue <- id:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *Thumbnailer) process(ctx context.Context, fileID uuid.UUID) error {
+	var f model.ChannelFile
+	if err := t.db.WithContext(ctx).Where("id = ?", fileID).First(&f).Error; err != nil {
+		return err
+	}
+	if f.PreviewStatus != PreviewPending || f.ScanStatus != ScanClean {
+		return nil
+	}
+	sizes, err := t.generate(ctx, &f)
+	status := PreviewReady
+	if err != nil {
+		status, sizes = PreviewFailed, nil
+	}
+	if uerr := t.db.WithContext(ctx).Model(&model.ChannelFile{}).Where("id = ?", f.ID).
+		Updates(map[string]interface{}{"preview_status": status, "preview_sizes": joinSizes(sizes)}).Error; uerr != nil {
+		return uerr
+	}
+	return err
+}
+
+// generate строит недостающие превью для содержимого f и возвращает готовые размеры.
+// Превью привязаны к SHA-256, поэтому файлы с одинаковым содержимым используют их совместно.
+func (t *Thumbnailer) generate(ctx context.Context, f *model.ChannelFile) ([]int, error) {
+	ext, _ := thumbFormat(f.ContentType)
+	var missing []int
+	for _, size := range t.sizes {
+		ok, err := t.store.Exists(ctx, thumbKey(f.SHA256, size, ext))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			missing = append(missing, size)
+		}
+	}
+	if len(missing) == 0 {
+		return t.sizes, nil
+	}
+	src, err := t.decode(ctx, f.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, size := range missing {
+		var buf bytes.Buffer
+		if err := encodeThumb(&buf, resize(src, size), ext); err != nil {
+			return nil, err
+		}
+		if err := t.store.Put(ctx, thumbKey(f.SHA256, size, ext), &buf); err != nil {
+			return nil, err
+		}
+	}
+	return t.sizes, nil
+}
+
+func (t *Thumbnailer) decode(ctx context.Context, key string) (image.Image, error) {
+	rc, err := t.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(rc)
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPreviewPixels {
+		return nil, fmt.Errorf("image %dx%d too large for preview", cfg.Width, cfg.Height)
+	}
+	rc, err = t.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	img, _, err := image.Decode(rc)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return img, nil
+}
+
+// Open возвращает превью файла ближайшего размера не меньше запрошенного (или наибольшее).
+func (t *Thumbnailer) Open(ctx context.Context, f *model.ChannelFile, want int) (io.ReadCloser, string, int, error) {
+	switch f.PreviewStatus {
+	case PreviewPending:
+		return nil, "", 0, ErrPreviewNotReady
+	case PreviewReady:
+	default:
+		return nil, "", 0, ErrPreviewUnavailable
+	}
+	ready := ParsePreviewSizes(f.PreviewSizes)
+	if len(ready) == 0 {
+		return nil, "", 0, ErrPreviewUnavailable
+	}
+	size := ready[len(ready)-1]
+	for _, s := range ready {
+		if s >= want {
+			size = s
+			break
+		}
+	}
+	ext, contentType := thumbFormat(f.ContentType)
+	rc, err := t.store.Open(ctx, thumbKey(f.SHA256, size, ext))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, "", 0, ErrPreviewUnavailable
+	}
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return rc, contentType, size, nil
+}
+
+// deleteFor удаляет превью содержимого digest (вызывается при освобождении последней ссылки).
+func (t *Thumbnailer) deleteFor(ctx context.Context, digest string) error {
+	for _, size := range t.sizes {
+		for _, ext := range []string{"jpg", "png"} {
+			if err := t.store.Delete(ctx, thumbKey(digest, size, ext)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func thumbKey(digest string, size int, ext string) string {
+	return "thumbs/" + digest + "/" + strconv.Itoa(size) + "." + ext
+}
+
+// thumbFormat: JPEG остаётся JPEG, остальные форматы кодируются в PNG, чтобы сохранить прозрачность.
+func thumbFormat(contentType string) (ext, mime string) {
+	if mediaType(contentType) == "image/jpeg" {
+		return "jpg", "image/jpeg"
+	}
+	return "png", "image/png"
+}
+
+func encodeThumb(w io.Writer, img image.Image, ext string) error {
+	if ext == "jpg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 82})
+	}
+	return png.Encode(w, img)
+}
+
+// resize вписывает изображение в квадрат size×size с сохранением пропорций; не увеличивает.
+func resize(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func joinSizes(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, s := range sizes {
+		parts[i] = strconv.Itoa(s)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParsePreviewSizes разбирает channel_files.preview_sizes ("128,512") в отсортированный список.
+func ParsePreviewSizes(v string) []int {
+	var out []int
+	for _, p := range strings.Split(v, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(p)); err == nil && n > 0 {
+			out = append(out, n)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrTransportClosed — соединение клиента уже закрыто.
+var ErrTransportClosed = errors.New("transport closed")
+
+// Transport — соединение с одним клиентом, поверх которого работает DataConn (WritePump, ReadPump).
+// Реализации: WebSocket (NewWSTransport) и память (NewMemTransport, для тестов без сети).
+type Transport interface {
+	// Send доставляет кадр клиенту.
+	Send(f Frame) error
+	// Receive ждёт следующий кадр клиента; ошибка — соединение закрыто.
+	Receive() ([]byte, error)
+	// Close закрывает соединение; code != 0 — клиенту сообщаются код и причина закрытия.
+	Close(code int, reason string) error
+}
+
+type wsTransport struct {
+	conn *websocket.Conn
+}
+
+// NewWSTransport — Transport поверх WebSocket: кадры — текстовые сообщения, закрытие — кадр Close.
+func NewWSTransport(conn *websocket.Conn) Transport {
+	return &wsTransport{conn: conn}
+}
+
+func (t *wsTransport) Send(f Frame) error {
+	return t.conn.WriteMessage(websocket.TextMessage, f.Data)
+}
+
+func (t *wsTransport) Receive() ([]byte, error) {
+	_, message, err := t.conn.ReadMessage()
+	return message, err
+}
+
+func (t *wsTransport) Close(code int, reason string) error {
+	if code != 0 {
+		const maxCloseReason = 123 // 125 байт управляющего кадра минус код
+		if len(reason) > maxCloseReason {
+			reason = reason[:maxCloseReason]
+		}
+		_ = t.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	}
+	return t.conn.Close()
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/psds-microservice/data-channel-service/internal/metastrip"
+	"github.com/psds-microservice/data-channel-service/internal/model"
+	"github.com/psds-microservice/data-channel-service/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Статусы upload_sessions.status.
+const (
+	UploadPending    = "pending"
+	UploadCompleting = "completing"
+	UploadCompleted  = "completed"
+	UploadFailed     = "failed"
+	UploadExpired    = "expired"
+)
+
+const (
+	defaultUploadURLTTL  = time.Hour
+	defaultPartSize      = 64 << 20
+	defaultDirectMaxSize = 5 << 30
+	minPartSize          = 5 << 20 // минимум S3 для всех частей, кроме последней
+	maxUploadParts       = 10000
+	// uploadCompleteGrace — сколько после истечения ссылок ещё можно вызвать CompleteUpload.
+	uploadCompleteGrace = time.Hour
+)
+
+var (
+	// ErrDirectUploadUnsupported — хранилище не поддерживает загрузку по подписанным ссылкам.
+	ErrDirectUploadUnsupported = errors.New("direct uploads are not supported by the storage backend")
+	// ErrUploadSessionNotFound — сессии загрузки нет или она принадлежит другому пользователю.
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+	// ErrUploadSessionClosed — сессия загрузки истекла, отменена или завершилась ошибкой.
+	ErrUploadSessionClosed = errors.New("upload session is closed")
+)
+
+// DirectUploadRequest — параметры CreateUploadSession.
+type DirectUploadRequest struct {
+	SessionID   uuid.UUID
+	UserID      uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+	SHA256      string // hex; проверяется в CompleteUpload
+}
+
+// PresignedPart — ссылка для загрузки одной части (Number с 1).
+type PresignedPart struct {
+	Number int
+	URL    string
+	Size   int64
+}
+
+// DirectUpload — созданная сессия загрузки: URL для одного PUT (с заголовками Headers) или ссылки на части multipart.
+type DirectUpload struct {
+	Session *model.UploadSession
+	URL     string
+	Headers map[string]string
+	Parts   []PresignedPart
+}
+
+// SetDirectUploads включает загрузку напрямую в хранилище; ttl — срок подписанных ссылок,
+// partSize — размер части multipart (файлы не больше partSize загружаются одним PUT),
+// maxSize — лимит размера прямой загрузки (лимит политики действует для загрузки через сервис).
+func (s *DataService) SetDirectUploads(d storage.DirectUploader, ttl time.Duration, partSize, maxSize int64) {
+	if ttl <= 0 {
+		ttl = defaultUploadURLTTL
+	}
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	if maxSize <= 0 {
+		maxSize = defaultDirectMaxSize
+	}
+	// Принятый объект переносится копированием внутри хранилища (promoteUpload).
+	maxSize = min(maxSize, storage.MaxCopySize)
+	s.direct, s.directTTL, s.partSize, s.directMax = d, ttl, max(partSize, minPartSize), maxSize
+}
+
+// directPolicy — политика приёма файлов с лимитом размера прямой загрузки.
+func (s *DataService) directPolicy() FilePolicy {
+	p := s.policy
+	p.MaxSizeBytes = s.directMax
+	return p
+}
+
+// CreateUploadSession проверяет метаданные будущего файла по политике и квотам и выдаёт
+// подписанные ссылки для загрузки содержимого в промежуточный объект uploads/<id>.
+func (s *DataService) CreateUploadSession(ctx context.Context, req DirectUploadRequest) (*DirectUpload, error) {
+	if s.direct == nil {
+		return nil, ErrDirectUploadUnsupported
+	}
+	if err := s.checkWriter(ctx, req.SessionID, req.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.directPolicy().CheckSize(req.Size); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Filename) == "" {
+		return nil, fmt.Errorf("%w: filename is required", ErrInvalidFile)
+	}
+	if req.ContentType != "" {
+		if err := s.policy.CheckType(req.ContentType); err != nil {
+			return nil, err
+		}
+	}
+	digest := strings.ToLower(req.SHA256)
+	sum, err := hex.DecodeString(digest)
+	if err != nil || len(sum) != 32 {
+		return nil, fmt.Errorf("%w: sha256 must be 64 hex characters", ErrInvalidFile)
+	}
+	if err := s.checkQuota(ctx, req.SessionID, req.UserID, req.Size); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	u := &model.UploadSession{
+		ID:          id,
+		SessionID:   req.SessionID,
+		UserID:      req.UserID,
+		Filename:    SanitizeFilename(req.Filename),
+		ContentType: mediaType(req.ContentType),
+		SizeBytes:   req.Size,
+		SHA256:      digest,
+		ObjectKey:   "uploads/" + id.String(),
+		Status:      UploadPending,
+		ExpiresAt:   time.Now().Add(s.directTTL),
+	}
+	out := &DirectUpload{Session: u}
+	if req.Size <= s.partSize {
+		// Хранилище само сверит SHA-256 при PUT: CompleteUpload не перечитывает содержимое.
+		checksum := base64.StdEncoding.EncodeToString(sum)
+		out.URL, err = s.direct.PresignPut(u.ObjectKey, s.directTTL, checksum)
+		out.Headers = map[string]string{"x-amz-checksum-sha256": checksum}
+	} else {
+		out.Parts, err = s.presignParts(ctx, u)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("presign upload: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Create(u).Error; err != nil {
+		if u.MultipartID != "" {
+			_ = s.direct.AbortMultipartUpload(ctx, u.ObjectKey, u.MultipartID)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *DataService) presignParts(ctx context.Context, u *model.UploadSession) ([]PresignedPart, error) {
+	count := (u.SizeBytes + s.partSize - 1) / s.partSize
+	if count > maxUploadParts {
+		return nil, fmt.Errorf("%w: %d parts of %d bytes exceed the limit of %d parts", ErrFileTooLarge, count, s.partSize, maxUploadParts)
+	}
+	uploadID, err := s.direct.CreateMultipartUpload(ctx, u.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	u.MultipartID = uploadID
+	parts := make([]PresignedPart, 0, count)
+	for i := int64(0); i < count; i++ {
+		url, err := s.direct.PresignUploadPart(u.ObjectKey, uploadID, int(i)+1, s.directTTL)
+		if err != nil {
+			_ = s.direct.AbortMultipartUpload(ctx, u.ObjectKey, uploadID)
+			return nil, err
+		}
+		parts = append(parts, PresignedPart{Number: int(i) + 1, URL: url, Size: min(s.partSize, u.SizeBytes-i*s.partSize)})
+	}
+	return parts, nil
+}
+
+// CompleteUpload проверяет загруженный объект (размер, SHA-256, тип по сигнатуре) и только после этого
+// создаёт строку channel_files и событие file.uploaded. Повторный вызов для завершённой сессии возвращает тот же файл.
+func (s *DataService) CompleteUpload(ctx context.Context, uploadID, userID uuid.UUID, parts []storage.Part) (*model.ChannelFile, error) {
+	if s.direct == nil {
+		return nil, ErrDirectUploadUnsupported
+	}
+	var u model.UploadSession
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", uploadID, userID).First(&u).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadSessionNotFound
+		}
+		return nil, err
+	}
+	if u.Status == UploadPending {
+		if err := s.checkSessionOpen(ctx, u.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	if u.MultipartID != "" && u.Status == UploadPending && len(parts) == 0 {
+		return nil, fmt.Errorf("%w: parts are required for a multipart upload", ErrInvalidFile)
+	}
+	res := s.db.WithContext(ctx).Model(&u).Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ? AND status = ? AND expires_at > ?", uploadID, userID, UploadPending, time.Now().Add(-uploadCompleteGrace)).
+		Updates(map[string]interface{}{"status": UploadCompleting, "updated_at": time.Now()})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return s.completedUpload(ctx, uploadID, userID)
+	}
+
+	f, err := s.finishUpload(ctx, &u, parts)
+	status := UploadCompleted
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	switch {
+	case err == nil:
+		updates["file_id"] = f.ID
+	case isUploadRejected(err):
+		// Содержимое не прошло проверку: объект удаляется, повторить можно только новой сессией.
+		status = UploadFailed
+		s.discardUpload(ctx, &u)
+	default:
+		// Временная ошибка (хранилище, БД): клиент может повторить CompleteUpload.
+		status = UploadPending
+	}
+	updates["status"] = status
+	if uerr := s.db.WithContext(ctx).Model(&model.UploadSession{}).Where("id = ?", u.ID).Updates(updates).Error; uerr != nil {
+		log.Printf("upload session %s: %v", u.ID, uerr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if derr := s.direct.Delete(ctx, u.ObjectKey); derr != nil {
+		log.Printf("upload session %s: delete staging object: %v", u.ID, derr)
+	}
+	return f, nil
+}
+
+// finishUpload принимает загруженный объект. Размер сверяется по HEAD, тип — по первым байтам (ranged GET),
+// SHA-256 — по сумме, проверенной хранилищем при PUT; объект становится blob копированием внутри хранилища.
+// Содержимое читается целиком, только если суммы нет (multipart), и переписывается через сервис,
+// только если его нужно изменить: очистка метаданных или шифрование (см. rewriteUpload).
+func (s *DataService) finishUpload(ctx context.Context, u *model.UploadSession, parts []storage.Part) (*model.ChannelFile, error) {
+	if u.MultipartID != "" {
+		// После временной ошибки повторный вызов застаёт объект уже собранным.
+		exists, err := s.direct.Exists(ctx, u.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err := s.direct.CompleteMultipartUpload(ctx, u.ObjectKey, u.MultipartID, parts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	info, err := s.direct.Stat(ctx, u.ObjectKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: object has not been uploaded", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Size != u.SizeBytes {
+		return nil, fmt.Errorf("%w: size mismatch: declared %d, received %d", ErrInvalidFile, u.SizeBytes, info.Size)
+	}
+	if err := s.checkWriter(ctx, u.SessionID, u.UserID); err != nil {
+		return nil, err
+	}
+	contentType, err := s.sniffUpload(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if s.rewriteUpload(contentType) {
+		return s.reuploadFile(ctx, u)
+	}
+	if err := s.verifyUploadChecksum(ctx, u, info); err != nil {
+		return nil, err
+	}
+	f := &model.ChannelFile{
+		SessionID:   u.SessionID,
+		UserID:      u.UserID,
+		Filename:    u.Filename,
+		ContentType: contentType,
+		SizeBytes:   u.SizeBytes,
+		SHA256:      u.SHA256,
+	}
+	s.initFileStatus(f)
+	var written blobWrites
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.chargeQuota(tx, u.SessionID, u.UserID, f.SizeBytes); err != nil {
+			return err
+		}
+		blob, err := s.acquireBlob(ctx, tx, &written, f.SHA256, f.SizeBytes, func(key string) error {
+			return s.direct.Copy(ctx, u.ObjectKey, key)
+		})
+		if err != nil {
+			return err
+		}
+		f.StoragePath = blob.StoragePath
+		return s.SaveFile(tx, f, s.directMax)
+	})
+	s.settleBlobWrites(ctx, written, err == nil)
+	if err != nil {
+		return nil, err
+	}
+	s.announceFile(ctx, f)
+	return f, nil
+}
+
+// sniffUpload определяет тип объекта по первым sniffLen байтам и сверяет его с заявленным и политикой.
+func (s *DataService) sniffUpload(ctx context.Context, u *model.UploadSession) (string, error) {
+	rc, err := s.direct.OpenRange(ctx, u.ObjectKey, 0, sniffLen)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	head, err := io.ReadAll(io.LimitReader(rc, sniffLen))
+	if err != nil {
+		return "", fmt.Errorf("read content: %w", err)
+	}
+	detected := DetectContentType(head)
+	if err := s.policy.CheckType(detected); err != nil {
+		return "", err
+	}
+	contentType, err := resolveContentType(u.ContentType, detected)
+	if err != nil {
+		return "", err
+	}
+	if err := s.policy.CheckType(contentType); err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+// rewriteUpload — содержимое нельзя перенести копированием: нужно удалить метаданные изображения
+// или blob хранится не в том же хранилище (шифрование at rest).
+func (s *DataService) rewriteUpload(contentType string) bool {
+	if s.sanitize.Enabled && metastrip.Supports(contentType) {
+		return true
+	}
+	return s.store != storage.Storage(s.direct)
+}
+
+// reuploadFile пропускает объект через общий конвейер UploadFile (чтение, SHA-256, очистка, запись).
+func (s *DataService) reuploadFile(ctx context.Context, u *model.UploadSession) (*model.ChannelFile, error) {
+	rc, err := s.direct.Open(ctx, u.ObjectKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: object has not been uploaded", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return s.UploadFile(ctx, Upload{
+		SessionID:      u.SessionID,
+		UserID:         u.UserID,
+		Filename:       u.Filename,
+		DeclaredType:   u.ContentType,
+		Size:           u.SizeBytes,
+		Content:        rc,
+		ExpectedSHA256: u.SHA256,
+		MaxSize:        s.directMax,
+	})
+}
+
+// verifyUploadChecksum сверяет SHA-256 объекта с заявленным: по сумме, проверенной хранилищем при PUT,
+// а если её нет (multipart) — потоковым чтением без копии на диске.
+func (s *DataService) verifyUploadChecksum(ctx context.Context, u *model.UploadSession, info storage.ObjectInfo) error {
+	var digest string
+	if info.ChecksumSHA256 != "" {
+		sum, err := base64.StdEncoding.DecodeString(info.ChecksumSHA256)
+		if err != nil {
+			return fmt.Errorf("storage checksum %q: %w", info.ChecksumSHA256, err)
+		}
+		digest = hex.EncodeToString(sum)
+	} else {
+		rc, err := s.direct.Open(ctx, u.ObjectKey)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, rc); err != nil {
+			return fmt.Errorf("read content: %w", err)
+		}
+		digest = hex.EncodeToString(h.Sum(nil))
+	}
+	if !strings.EqualFold(digest, u.SHA256) {
+		return fmt.Errorf("%w: declared sha256 %s, received %s", ErrChecksumMismatch, u.SHA256, digest)
+	}
+	return nil
+}
+
+// completedUpload обрабатывает CompleteUpload для сессии, которая уже не в статусе pending.
+func (s *DataService) completedUpload(ctx context.Context, uploadID, userID uuid.UUID) (*model.ChannelFile, error) {
+	var u model.UploadSession
+	err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", uploadID, userID).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUploadSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case u.Status == UploadCompleted && u.FileID != nil:
+		return s.GetFile(ctx, *u.FileID)
+	case u.Status == UploadCompleting:
+		return nil, fmt.Errorf("%w: completion already in progress", ErrUploadSessionClosed)
+	case u.Status == UploadPending:
+		return nil, fmt.Errorf("%w: upload links expired at %s", ErrUploadSessionClosed, u.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUploadSessionClosed, u.Status)
+}
+
+// ExpireUploadSessions отменяет незавершённые сессии загрузки с истёкшими ссылками
+// и удаляет их промежуточные объекты. Вызывается из FileGC.
+func (s *DataService) ExpireUploadSessions(ctx context.Context) (int, error) {
+	if s.direct == nil {
+		return 0, nil
+	}
+	var list []model.UploadSession
+	now := time.Now()
+	if err := s.db.WithContext(ctx).
+		Where("(status = ? AND expires_at < ?) OR (status = ? AND updated_at < ?)",
+			UploadPending, now.Add(-uploadCompleteGrace), UploadCompleting, now.Add(-uploadCompleteGrace)).
+		Limit(gcBatchSize).Find(&list).Error; err != nil {
+		return 0, err
+	}
+	for i := range list {
+		s.discardUpload(ctx, &list[i])
+		if err := s.db.WithContext(ctx).Model(&model.UploadSession{}).Where("id = ? AND status = ?", list[i].ID, list[i].Status).
+			Updates(map[string]interface{}{"status": UploadExpired, "updated_at": time.Now()}).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(list), nil
+}
+
+func (s *DataService) discardUpload(ctx context.Context, u *model.UploadSession) {
+	if u.MultipartID != "" {
+		if err := s.direct.AbortMultipartUpload(ctx, u.ObjectKey, u.MultipartID); err != nil {
+			log.Printf("upload session %s: abort multipart: %v", u.ID, err)
+		}
+	}
+	if err := s.direct.Delete(ctx, u.ObjectKey); err != nil {
+		log.Printf("upload session %s: delete staging object: %v", u.ID, err)
+	}
+}
+
+// checkQuota проверяет, поместится ли файл в квоты, ничего не учитывая: списание откатывается.
+func (s *DataService) checkQuota(ctx context.Context, sessionID, userID uuid.UUID, size int64) error {
+	errDryRun := errors.New("dry run")
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.chargeQuota(tx, sessionID, userID, size); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+func isUploadRejected(err error) bool {
+	return errors.Is(err, ErrInvalidFile) || errors.Is(err, ErrFileTooLarge) ||
+		errors.Is(err, ErrFileTypeNotAllowed) || errors.Is(err, ErrContentTypeMismatch) ||
+		errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrQuotaExceeded)
+}
@@ -0,0 +1,395 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Part — загруженная часть multipart-загрузки: номер (с 1) и ETag из ответа хранилища.
+type Part struct {
+	Number int
+	ETag   string
+}
+
+// ObjectInfo — метаданные объекта (HEAD). ChecksumSHA256 — SHA-256 содержимого в base64, если хранилище
+// проверило его при загрузке (x-amz-checksum-sha256); пусто — контрольной суммы нет.
+type ObjectInfo struct {
+	Size           int64
+	ChecksumSHA256 string
+}
+
+// MaxCopySize — наибольший объект, который S3 копирует одним запросом (CopyObject).
+const MaxCopySize = 5 << 30
+
+// DirectUploader — хранилище, принимающее содержимое от клиентов напрямую по подписанным ссылкам.
+type DirectUploader interface {
+	Storage
+	// PresignPut — ссылка для одного PUT; с checksumSHA256 (base64) хранилище отклонит содержимое
+	// с другой суммой, а клиент должен передать её в заголовке x-amz-checksum-sha256.
+	PresignPut(key string, ttl time.Duration, checksumSHA256 string) (string, error)
+	CreateMultipartUpload(ctx context.Context, key string) (string, error)
+	PresignUploadPart(key, uploadID string, partNumber int, ttl time.Duration) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+	Stat(ctx context.Context, key string) (ObjectInfo, error) // ErrNotFound, если объекта нет
+	OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Copy(ctx context.Context, src, dst string) error // копирование внутри хранилища, до MaxCopySize
+}
+
+// S3Config — параметры S3-совместимого хранилища (AWS S3, MinIO, Ceph RGW).
+type S3Config struct {
+	Endpoint  string // https://s3.eu-central-1.amazonaws.com, http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // endpoint/bucket/key вместо bucket.endpoint/key (MinIO)
+}
+
+// S3 хранит объекты в бакете S3-совместимого хранилища; запросы подписываются SigV4.
+type S3 struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    sigV4
+	client    *http.Client
+}
+
+// NewS3 создаёт клиент бакета.
+func NewS3(cfg S3Config) (*S3, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("storage: invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("storage: S3 bucket and credentials are required")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3{
+		endpoint:  u,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		signer:    sigV4{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: region, service: "s3"},
+		client:    &http.Client{Timeout: 0}, // длительность ограничивается ctx запроса
+	}, nil
+}
+
+func (s *S3) objectURL(key string, q url.Values) (*url.URL, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") {
+		return nil, fmt.Errorf("storage: invalid key %q", key)
+	}
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(q)
+	return &u, nil
+}
+
+func (s *S3) do(ctx context.Context, method, key string, q url.Values, body io.Reader, size int64) (*http.Response, error) {
+	return s.doWithHeaders(ctx, method, key, q, nil, body, size)
+}
+
+func (s *S3) doWithHeaders(ctx context.Context, method, key string, q url.Values, hdr http.Header, body io.Reader, size int64) (*http.Response, error) {
+	u, err := s.objectURL(key, q)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range hdr {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.signer.signRequest(req, time.Now())
+	return s.client.Do(req)
+}
+
+// Put загружает объект одним PUT. S3 требует Content-Length, поэтому поток неизвестной длины
+// сначала сохраняется во временный файл.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader) error {
+	rs, size, cleanup, err := sizedReader(r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	resp, err := s.do(ctx, http.MethodPut, key, nil, rs, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3Error(resp, http.StatusOK)
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// OpenRange читает length байт объекта начиная с offset (меньше — если объект короче).
+func (s *S3) OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	hdr := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := s.doWithHeaders(ctx, http.MethodGet, key, nil, hdr, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil // пустой объект
+	}
+	if err := s3Error(resp, http.StatusPartialContent, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat возвращает размер объекта и его SHA-256, если хранилище проверяло сумму при загрузке.
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	hdr := http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}}
+	resp, err := s.doWithHeaders(ctx, http.MethodHead, key, nil, hdr, nil, 0)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		return ObjectInfo{}, err
+	}
+	info := ObjectInfo{Size: resp.ContentLength}
+	// Составная сумма multipart ("<base64>-<части>") — не SHA-256 содержимого.
+	if sum := resp.Header.Get("X-Amz-Checksum-Sha256"); !strings.Contains(sum, "-") {
+		info.ChecksumSHA256 = sum
+	}
+	return info, nil
+}
+
+// Copy копирует объект src в dst на стороне хранилища (CopyObject).
+func (s *S3) Copy(ctx context.Context, src, dst string) error {
+	if _, err := s.objectURL(src, nil); err != nil {
+		return err
+	}
+	hdr := http.Header{"X-Amz-Copy-Source": {uriEncode("/"+s.bucket+"/"+src, false)}}
+	resp, err := s.doWithHeaders(ctx, http.MethodPut, dst, nil, hdr, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		return err
+	}
+	// Как и CompleteMultipartUpload, CopyObject может вернуть 200 с <Error> в теле.
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(raw, []byte("<Error>")) {
+		return fmt.Errorf("storage: copy object: %s", strings.TrimSpace(string(raw)))
+	}
+	return nil
+}
+
+func (s *S3) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, 0)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return true, s3Error(resp, http.StatusOK)
+}
+
+// Delete удаляет объект; отсутствие объекта не считается ошибкой.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3Error(resp, http.StatusNoContent, http.StatusOK)
+}
+
+// PresignPut — ссылка для загрузки объекта одним PUT; непустая checksumSHA256 подписывается
+// заголовком x-amz-checksum-sha256, и S3 отклоняет содержимое с другой суммой.
+func (s *S3) PresignPut(key string, ttl time.Duration, checksumSHA256 string) (string, error) {
+	u, err := s.objectURL(key, nil)
+	if err != nil {
+		return "", err
+	}
+	var headers map[string]string
+	if checksumSHA256 != "" {
+		headers = map[string]string{"x-amz-checksum-sha256": checksumSHA256}
+	}
+	return s.signer.presign(http.MethodPut, u, ttl, time.Now(), headers), nil
+}
+
+// CreateMultipartUpload начинает multipart-загрузку и возвращает её UploadId.
+func (s *S3) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		return "", err
+	}
+	var out struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil || out.UploadID == "" {
+		return "", fmt.Errorf("storage: create multipart upload: bad response: %v", err)
+	}
+	return out.UploadID, nil
+}
+
+// PresignUploadPart — ссылка для PUT части partNumber.
+func (s *S3) PresignUploadPart(key, uploadID string, partNumber int, ttl time.Duration) (string, error) {
+	u, err := s.objectURL(key, url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}})
+	if err != nil {
+		return "", err
+	}
+	return s.signer.presign(http.MethodPut, u, ttl, time.Now(), nil), nil
+}
+
+// CompleteMultipartUpload собирает объект из частей.
+func (s *S3) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) error {
+	type xmlPart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	body := struct {
+		XMLName xml.Name  `xml:"CompleteMultipartUpload"`
+		Parts   []xmlPart `xml:"Part"`
+	}{}
+	for _, p := range parts {
+		body.Parts = append(body.Parts, xmlPart{PartNumber: p.Number, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		return err
+	}
+	// S3 может вернуть 200 с <Error> в теле, если сборка не удалась.
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(raw, []byte("<Error>")) {
+		return fmt.Errorf("storage: complete multipart upload: %s", strings.TrimSpace(string(raw)))
+	}
+	return nil
+}
+
+// AbortMultipartUpload отменяет загрузку и освобождает загруженные части.
+func (s *S3) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3Error(resp, http.StatusNoContent, http.StatusOK)
+}
+
+// s3Error превращает неожиданный статус ответа в ошибку с кодом и сообщением S3.
+func s3Error(resp *http.Response, ok ...int) error {
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+	if e.Code != "" {
+		return fmt.Errorf("storage: s3 %s: %s: %s", resp.Status, e.Code, e.Message)
+	}
+	return fmt.Errorf("storage: s3 %s", resp.Status)
+}
+
+// sizedReader возвращает поток с известной длиной: ReadSeeker используется как есть,
+// остальное копируется во временный файл.
+func sizedReader(r io.Reader) (io.Reader, int64, func(), error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		cur, err := rs.Seek(0, io.SeekCurrent)
+		if err == nil {
+			var end int64
+			if end, err = rs.Seek(0, io.SeekEnd); err == nil {
+				_, err = rs.Seek(cur, io.SeekStart)
+				return rs, end - cur, func() {}, err
+			}
+		}
+	}
+	tmp, err := os.CreateTemp("", "s3-put-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup := func() { tmp.Close(); os.Remove(tmp.Name()) }
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return tmp, n, cleanup, nil
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound — объект с указанным ключом отсутствует в хранилище.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage — хранилище бинарных объектов по ключу (локальный диск, S3-совместимое и т.п.).
+// Ключи — относительные пути через "/", без "..".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
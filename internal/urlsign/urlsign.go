@@ -0,0 +1,131 @@
+package urlsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// minSecretLen — минимальная длина секрета HMAC в байтах.
+const minSecretLen = 32
+
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("link expired")
+)
+
+// Key — секрет подписи с идентификатором; идентификатор передаётся в ссылке (kid) для ротации.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Signer подписывает ссылки на файлы HMAC-SHA256. Первый ключ — активный,
+// остальные принимаются только при проверке, пока не истекут выданные ими ссылки.
+type Signer struct {
+	keys []Key
+}
+
+// ParseKeys разбирает список "kid:secret,kid2:secret2".
+func ParseKeys(spec string) ([]Key, error) {
+	var keys []Key
+	seen := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("url signing key %q: expected kid:secret", item)
+		}
+		if len(secret) < minSecretLen {
+			return nil, fmt.Errorf("url signing key %q: secret must be at least %d bytes", id, minSecretLen)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("url signing key %q: duplicate kid", id)
+		}
+		seen[id] = true
+		keys = append(keys, Key{ID: id, Secret: []byte(secret)})
+	}
+	return keys, nil
+}
+
+// NewSigner создаёт подписыватель; keys[0] используется для новых ссылок.
+func NewSigner(keys []Key) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("url signing: no keys")
+	}
+	return &Signer{keys: keys}, nil
+}
+
+// Sign возвращает параметры запроса (exp, uid, kid, sig) для ссылки на файл fileID.
+// Если userID задан, ссылка действительна только для этого пользователя.
+func (s *Signer) Sign(fileID uuid.UUID, expires time.Time, userID *uuid.UUID) url.Values {
+	k := s.keys[0]
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	uid := ""
+	if userID != nil {
+		uid = userID.String()
+	}
+	q := url.Values{}
+	q.Set("exp", exp)
+	if uid != "" {
+		q.Set("uid", uid)
+	}
+	q.Set("kid", k.ID)
+	q.Set("sig", sign(k.Secret, fileID.String(), exp, uid))
+	return q
+}
+
+// Verify проверяет подпись и срок ссылки на fileID и возвращает привязанного пользователя (nil — не привязана).
+func (s *Signer) Verify(fileID uuid.UUID, q url.Values, now time.Time) (*uuid.UUID, error) {
+	sig := q.Get("sig")
+	if sig == "" {
+		return nil, ErrMissingSignature
+	}
+	exp, uid, kid := q.Get("exp"), q.Get("uid"), q.Get("kid")
+	var key *Key
+	for i := range s.keys {
+		if s.keys[i].ID == kid {
+			key = &s.keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(sign(key.Secret, fileID.String(), exp, uid))) {
+		return nil, ErrInvalidSignature
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if now.Unix() > expUnix {
+		return nil, ErrExpired
+	}
+	if uid == "" {
+		return nil, nil
+	}
+	bound, err := uuid.Parse(uid)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &bound, nil
+}
+
+func sign(secret []byte, fileID, exp, uid string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("v1\n" + fileID + "\n" + exp + "\n" + uid))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
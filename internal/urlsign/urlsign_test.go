@@ -0,0 +1,119 @@
+package urlsign
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	secretA = strings.Repeat("a", minSecretLen)
+	secretB = strings.Repeat("b", minSecretLen)
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    string
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "empty", spec: ""},
+		{name: "one", spec: "k1:" + secretA, wantIDs: []string{"k1"}},
+		{name: "rotation order kept", spec: " k2:" + secretB + " , k1:" + secretA, wantIDs: []string{"k2", "k1"}},
+		{name: "no separator", spec: secretA, wantErr: "expected kid:secret"},
+		{name: "empty kid", spec: ":" + secretA, wantErr: "expected kid:secret"},
+		{name: "short secret", spec: "k1:short", wantErr: "at least"},
+		{name: "duplicate kid", spec: "k1:" + secretA + ",k1:" + secretB, wantErr: "duplicate kid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := ParseKeys(tc.spec)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseKeys error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != len(tc.wantIDs) {
+				t.Fatalf("keys = %d, want %d", len(keys), len(tc.wantIDs))
+			}
+			for i, id := range tc.wantIDs {
+				if keys[i].ID != id {
+					t.Fatalf("keys[%d].ID = %q, want %q", i, keys[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	fileID, otherFile, user := uuid.New(), uuid.New(), uuid.New()
+	current, err := NewSigner([]Key{{ID: "k2", Secret: []byte(secretB)}, {ID: "k1", Secret: []byte(secretA)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, _ := NewSigner([]Key{{ID: "k1", Secret: []byte(secretA)}})
+	foreign, _ := NewSigner([]Key{{ID: "k2", Secret: []byte(secretA)}})
+
+	valid := func() url.Values { return current.Sign(fileID, now.Add(time.Minute), nil) }
+	with := func(q url.Values, key, value string) url.Values {
+		q.Set(key, value)
+		return q
+	}
+	without := func(q url.Values, key string) url.Values {
+		q.Del(key)
+		return q
+	}
+	cases := []struct {
+		name     string
+		fileID   uuid.UUID
+		q        url.Values
+		now      time.Time
+		wantUser *uuid.UUID
+		wantErr  error
+	}{
+		{name: "valid", fileID: fileID, q: valid(), now: now},
+		{name: "bound to user", fileID: fileID, q: current.Sign(fileID, now.Add(time.Minute), &user), now: now, wantUser: &user},
+		{name: "signed by rotated key", fileID: fileID, q: retired.Sign(fileID, now.Add(time.Minute), nil), now: now},
+		{name: "at expiry second", fileID: fileID, q: valid(), now: now.Add(time.Minute)},
+		{name: "expired", fileID: fileID, q: valid(), now: now.Add(time.Minute + time.Second), wantErr: ErrExpired},
+		{name: "missing signature", fileID: fileID, q: without(valid(), "sig"), now: now, wantErr: ErrMissingSignature},
+		{name: "other file", fileID: otherFile, q: valid(), now: now, wantErr: ErrInvalidSignature},
+		{name: "extended expiry", fileID: fileID, q: with(valid(), "exp", "9999999999"), now: now, wantErr: ErrInvalidSignature},
+		{name: "user added", fileID: fileID, q: with(valid(), "uid", user.String()), now: now, wantErr: ErrInvalidSignature},
+		{name: "user removed", fileID: fileID, q: without(current.Sign(fileID, now.Add(time.Minute), &user), "uid"), now: now, wantErr: ErrInvalidSignature},
+		{name: "unknown kid", fileID: fileID, q: with(valid(), "kid", "k3"), now: now, wantErr: ErrInvalidSignature},
+		{name: "kid swapped", fileID: fileID, q: with(valid(), "kid", "k1"), now: now, wantErr: ErrInvalidSignature},
+		{name: "foreign secret", fileID: fileID, q: foreign.Sign(fileID, now.Add(time.Minute), nil), now: now, wantErr: ErrInvalidSignature},
+		{name: "garbage signature", fileID: fileID, q: with(valid(), "sig", "AAAA"), now: now, wantErr: ErrInvalidSignature},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := current.Verify(tc.fileID, tc.q, tc.now)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tc.wantErr)
+			}
+			switch {
+			case tc.wantUser == nil && got != nil:
+				t.Fatalf("Verify user = %v, want none", got)
+			case tc.wantUser != nil && (got == nil || *got != *tc.wantUser):
+				t.Fatalf("Verify user = %v, want %v", got, tc.wantUser)
+			}
+		})
+	}
+}
+
+func TestNewSignerRequiresKey(t *testing.T) {
+	if _, err := NewSigner(nil); err == nil {
+		t.Fatal("NewSigner(nil) succeeded")
+	}
+}
@@ -4,4 +4,5 @@ const (
 	PathHealth  = "/health"
 	PathReady   = "/ready"
 	PathSwagger = "/swagger"
+	PathMetrics = "/metrics"
 )